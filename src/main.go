@@ -5,122 +5,540 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"math/rand/v2"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"src/sim/page"
 	"src/sim/process"
+	"strconv"
 	"strings"
+	"time"
 )
 
 var (
 	sim_processes      = flag.Bool("sim-processes", false, "run the process simulation")
 	sim_pages          = flag.Bool("sim-pages", false, "run the page simulation")
+	sim_tasks          = flag.Bool("sim-tasks", false, "run the periodic real-time task simulation")
+	seed               = flag.Uint64("seed", 0, "seed for generating the processes, tasks and page reference pattern, the same seed and parameters always generate the same input, 0 picks a random one, it's logged and saved to metadata.csv next to the results")
 	num_processes      = flag.Uint("num-processes", 128, "number of processes to be generated")
+	process_input      = flag.String("process-input", "", "csv file to read the processes from instead of generating them, either a process.csv saved by a simulation, or one with the id, arrive time and execution time of a process on every line")
+	swf_input          = flag.String("swf-input", "", "trace in the Standard Workload Format from the Parallel Workloads Archive to read the processes from instead of generating them")
+	swf_time_scale     = flag.Float64("swf-time-scale", 1, "units of time of the simulation per second of the swf-input trace, for example 0.0166 makes a unit of time about a minute")
+	swf_max_jobs       = flag.Uint("swf-max-jobs", 0, "number of jobs read from the start of the swf-input trace, 0 reads all of them")
+	swf_max_threads    = flag.Uint("swf-max-threads", 1, "maximum number of processes a job from the swf-input trace gets, one for every processor it requested, in the same address space")
 	max_arrive_time    = flag.Uint("max-arrive-time", 256, "maximum time at which a process 'arrives' to be scheduled")
 	max_execution_time = flag.Uint("max-execution-time", 16, "maximum execution time for a generated process")
+	arrivals           = flag.String("arrivals", "uniform", "how the arrive times of generated processes are drawn: uniform between 0 and max-arrive-time, or poisson, with exponential gaps that average max-arrive-time/num-processes")
+	burst_distribution = flag.String("burst-distribution", "uniform", "distribution of the cpu burst lengths of generated processes: uniform, exponential, lognormal, pareto or bimodal, all of them are cut off at max-execution-time")
+	burst_mean         = flag.Float64("burst-mean", 8, "mean cpu burst length of the exponential, lognormal and pareto distributions, and of the short bursts of the bimodal one")
+	burst_sigma        = flag.Float64("burst-sigma", 1, "standard deviation of the logarithm of the cpu burst lengths of the lognormal distribution")
+	pareto_shape       = flag.Float64("pareto-shape", 1.5, "shape of the pareto distribution of cpu burst lengths, has to be greater than 1, the closer to 1 the heavier the tail")
+	bimodal_long_mean  = flag.Float64("bimodal-long-mean", 64, "mean length of the long cpu bursts of the bimodal distribution")
+	bimodal_long_part  = flag.Float64("bimodal-long-fraction", 0.1, "fraction of the cpu bursts of the bimodal distribution that are long, between 0 and 1")
+	max_io_bursts      = flag.Uint("max-io-bursts", 0, "maximum number of i/o bursts of a generated process, each one between two cpu bursts, 0 makes every process a single cpu burst")
+	max_io_time        = flag.Uint("max-io-time", 16, "maximum length of an i/o burst for a generated process")
+	engine             = flag.String("engine", "events", "how the process simulation moves through time: events jumps from one event to the next, ticks goes one unit of time at a time")
+	bench              = flag.Bool("bench", false, "run the process simulation with both engines, log how long each of them took, and check that their results are the same")
+	cores              = flag.Uint("cores", 1, "number of cores to simulate the processes on, every core gets it's own run queue")
+	balancing          = flag.String("balancing", "pull", "how processes are balanced between cores: none, push, pull or steal")
+	balance_interval   = flag.Uint("balance-interval", 16, "interval at which push balancing moves processes between cores")
+	migration_cost     = flag.Uint("migration-cost", 2, "time a core spends on a process moved to it from another core before it can execute it")
+	switch_cost        = flag.Uint("context-switch-cost", 0, "time a cpu spends switching from one process to another before it can execute it")
+	address_space_cost = flag.Uint("address-space-cost", 0, "time a cpu spends on a context switch on top of context-switch-cost, when the new process is in another address space")
+	address_spaces     = flag.Uint("address-spaces", 0, "number of address spaces the generated processes are spread over, 0 gives every process it's own")
+	num_tasks          = flag.Uint("num-tasks", 8, "number of periodic tasks to be generated")
+	min_period         = flag.Uint("min-period", 4, "minimum period of a generated task")
+	max_period         = flag.Uint("max-period", 64, "maximum period of a generated task")
+	utilization        = flag.Float64("utilization", 0.8, "part of the cpu the generated tasks use together")
+	constrained        = flag.Bool("constrained-deadlines", false, "generate task deadlines between the wcet and the period, instead of equal to the period")
+	horizon            = flag.Uint("horizon", 1024, "time until which the tasks release jobs")
 	num_pages          = flag.Uint("num-pages", 64, "amount of pages in virtual memory")
 	total_refs         = flag.Uint("total-refs", 512, "amount of virtual memory accesses")
+	page_generator     = flag.String("page-generator", "normal", "how the page reference pattern is generated: normal draws every reference from a normal distribution over the pages, locality goes through phases with their own working sets")
+	working_set_size   = flag.Uint("working-set-size", 8, "number of consecutive pages in the working set of a phase of the locality page generator")
+	phase_length       = flag.Uint("phase-length", 64, "number of references in a phase of the locality page generator")
+	phase_transition   = flag.Float64("phase-transition", 0.5, "probability that the locality page generator moves to a new working set at the end of a phase, between 0 and 1")
+	sequential_refs    = flag.Float64("sequential-refs", 0.1, "fraction of the references of the locality page generator that go through the pages one after another, past the working set too")
+	loop_refs          = flag.Float64("loop-refs", 0.3, "fraction of the references of the locality page generator that loop over the working set in order, the rest are to random pages of the working set")
+	aging_bits         = flag.Uint("aging-bits", 8, "width in bits of the counter of every page in memory for the Aging page replacement algorithm, between 1 and 64")
+	page_input         = flag.String("page-input", "", "file to read the page reference pattern from instead of generating it, either a pageReferencePattern.csv saved by a simulation, or a trace of page numbers separated by spaces or new lines")
+	priority_levels    = flag.Uint("priority-levels", 8, "number of priority levels a generated process can have, 0 is the most important")
+	aging_rate         = flag.Uint("aging-rate", 0, "if not 0, the priority schedulers are also simulated with aging, where a waiting process gains a priority level every aging-rate units of time")
+	max_tickets        = flag.Uint("max-tickets", 100, "maximum amount of lottery and stride scheduling tickets for a generated process")
+	max_nice           = flag.Uint("max-nice", 5, "generated processes get a nice value for CFS between -max-nice and max-nice")
+	cfs_latency        = flag.Uint("cfs-latency", 24, "CFS target latency, the time in which every ready process should get the cpu once")
+	cfs_granularity    = flag.Uint("cfs-granularity", 3, "CFS minimum granularity, the shortest time a process can get the cpu for")
+	lottery_seed       = flag.Uint64("lottery-seed", 1, "seed for drawing the lottery scheduling tickets")
+	sjf_alpha          = flag.Float64("sjf-alpha", 0.5, "weight of the last cpu burst in the exponential averaging of the predictive SJF, between 0 and 1")
+	sjf_initial_guess  = flag.Float64("sjf-initial-guess", 8, "predicted length of the first cpu burst of every process for the predictive SJF")
+	quanta             = flag.String("quanta", "4", "comma separated list of round robin, lottery and stride time quanta, each one is simulated separately")
+	mlfq_quanta        = flag.String("mlfq-quanta", "2,4,8", "comma separated list of MLFQ time quanta, one for every queue from the most important one")
+	mlfq_boost         = flag.Uint("mlfq-boost", 64, "interval at which MLFQ moves every process back to the most important queue, 0 disables the boost")
 )
 
+// the streams of the seed used by every generator, so that what one of them generates does not depend on which other simulations were run
+const (
+	PROCESS_STREAM = iota
+	TASK_STREAM
+	PAGE_STREAM
+)
+
+// parseQuanta parses a comma separated list of time quanta from the flag called name
+func parseQuanta(name, s string) (res []uint32) {
+	for _, field := range strings.Split(s, ",") {
+		quantum, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32)
+		if err != nil || quantum == 0 {
+			log.Panicf("%s has to be a list of 32 bit unsigned integers, only values between %d and %d are allowed, got: %q", name, 1, math.MaxUint32, field)
+		}
+		res = append(res, uint32(quantum))
+	}
+	return res
+}
+
+// benchEngines times the simulation with both engines, and panics if their results differ from the ones we already have
+func benchEngines(simulate func(process.Engine) ([]*process.Slice, []*process.CoreSlice, []*process.Timeline),
+	processResults []*process.Slice, coreResults []*process.CoreSlice, timelines []*process.Timeline) {
+	log.Println("Benchmarking the process simulation engines...")
+	durations := make(map[process.Engine]time.Duration)
+	for _, e := range []process.Engine{process.Events, process.Ticks} {
+		start := time.Now()
+		res, cores, tl := simulate(e)
+		durations[e] = time.Since(start)
+		for i := range res {
+			if !reflect.DeepEqual(res[i].Records(), processResults[i].Records()) ||
+				!reflect.DeepEqual(cores[i].Records(), coreResults[i].Records()) ||
+				!reflect.DeepEqual(tl[i].Records(), timelines[i].Records()) {
+				log.Panicf("The %s engine gave different results than the other one", e)
+			}
+		}
+		log.Printf("%s engine: %v", e, durations[e])
+	}
+	log.Printf("The events engine was %.2f times faster than the ticks engine, and both gave the same results\n\n",
+		float64(durations[process.Ticks])/float64(durations[process.Events]))
+}
+
 func main() {
 	flag.Parse()
 	switch {
-	case *num_processes != 128 && *num_processes > math.MaxUint16:
-		log.Panicf("num-processes has to be a 16 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint16)
-	case *max_arrive_time != 256 && *max_arrive_time > math.MaxUint16:
-		log.Panicf("max-arrive-time has to be a 16 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint16)
-	case *max_execution_time != 16 && *max_execution_time > math.MaxUint16:
-		log.Panicf("max-execution-time has to be a 16 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint16)
-	case *num_pages != 64 && *num_pages > math.MaxUint16:
-		log.Panicf("num-pages has to be a 16 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint16)
-	case *total_refs != 512 && *total_refs > math.MaxUint16:
-		log.Panicf("total-refs has to be a 16 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint16)
-	case !*sim_processes && !*sim_pages:
+	case *num_processes != 128 && *num_processes > math.MaxUint32:
+		log.Panicf("num-processes has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *max_arrive_time != 256 && *max_arrive_time > math.MaxUint32:
+		log.Panicf("max-arrive-time has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *max_execution_time != 16 && *max_execution_time > math.MaxUint32:
+		log.Panicf("max-execution-time has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *max_io_bursts != 0 && *max_io_bursts > math.MaxUint32:
+		log.Panicf("max-io-bursts has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *max_io_time != 16 && *max_io_time > math.MaxUint32:
+		log.Panicf("max-io-time has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *priority_levels != 8 && *priority_levels > math.MaxUint32:
+		log.Panicf("priority-levels has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *max_tickets != 100 && *max_tickets > math.MaxUint32:
+		log.Panicf("max-tickets has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *max_nice > process.MAX_NICE:
+		log.Panicf("max-nice has to be a valid nice value, only values between %d and %d are allowed", 0, process.MAX_NICE)
+	case *cfs_latency != 24 && *cfs_latency > math.MaxUint32:
+		log.Panicf("cfs-latency has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *cfs_granularity != 3 && *cfs_granularity > math.MaxUint32:
+		log.Panicf("cfs-granularity has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *aging_rate != 0 && *aging_rate > math.MaxUint32:
+		log.Panicf("aging-rate has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *mlfq_boost != 64 && *mlfq_boost > math.MaxUint32:
+		log.Panicf("mlfq-boost has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *num_pages != 64 && *num_pages > math.MaxUint32:
+		log.Panicf("num-pages has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *total_refs != 512 && *total_refs > math.MaxUint32:
+		log.Panicf("total-refs has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *aging_bits == 0 || *aging_bits > 64:
+		log.Panicf("aging-bits has to be between %d and %d", 1, 64)
+	case *working_set_size == 0 || *working_set_size > math.MaxUint32:
+		log.Panicf("working-set-size has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 1, math.MaxUint32)
+	case *phase_length == 0 || *phase_length > math.MaxUint32:
+		log.Panicf("phase-length has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 1, math.MaxUint32)
+	case *phase_transition < 0 || *phase_transition > 1:
+		log.Panicf("phase-transition has to be between %d and %d", 0, 1)
+	case *sequential_refs < 0 || *loop_refs < 0 || *sequential_refs+*loop_refs > 1:
+		log.Panicf("sequential-refs and loop-refs have to be between %d and %d, and cannot add up to more than %d", 0, 1, 1)
+	case *cores == 0 || *cores > math.MaxUint32:
+		log.Panicf("cores has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 1, math.MaxUint32)
+	case *balance_interval == 0 || *balance_interval > math.MaxUint32:
+		log.Panicf("balance-interval has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 1, math.MaxUint32)
+	case *migration_cost != 2 && *migration_cost > math.MaxUint32:
+		log.Panicf("migration-cost has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *switch_cost != 0 && *switch_cost > math.MaxUint32:
+		log.Panicf("context-switch-cost has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *address_space_cost != 0 && *address_space_cost > math.MaxUint32:
+		log.Panicf("address-space-cost has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *address_spaces != 0 && *address_spaces > math.MaxUint32:
+		log.Panicf("address-spaces has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *num_tasks != 8 && *num_tasks > math.MaxUint32:
+		log.Panicf("num-tasks has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *min_period != 4 && *min_period > math.MaxUint32:
+		log.Panicf("min-period has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *max_period != 64 && *max_period > math.MaxUint32:
+		log.Panicf("max-period has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *horizon != 1024 && *horizon > math.MaxUint32:
+		log.Panicf("horizon has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *sjf_alpha < 0 || *sjf_alpha > 1:
+		log.Panicf("sjf-alpha has to be between %d and %d", 0, 1)
+	case *sjf_initial_guess < 0:
+		log.Panic("sjf-initial-guess cannot be negative")
+	case *process_input != "" && *swf_input != "":
+		log.Panic("process-input and swf-input cannot be used together, the processes can only come from one of them")
+	case *swf_time_scale <= 0:
+		log.Panic("swf-time-scale has to be greater than zero")
+	case *burst_mean <= 0:
+		log.Panic("burst-mean has to be greater than zero")
+	case *burst_sigma < 0:
+		log.Panic("burst-sigma cannot be negative")
+	case *pareto_shape <= 1:
+		log.Panic("pareto-shape has to be greater than 1")
+	case *bimodal_long_mean <= 0:
+		log.Panic("bimodal-long-mean has to be greater than zero")
+	case *bimodal_long_part < 0 || *bimodal_long_part > 1:
+		log.Panicf("bimodal-long-fraction has to be between %d and %d", 0, 1)
+	case *swf_max_jobs != 0 && *swf_max_jobs > math.MaxUint32:
+		log.Panicf("swf-max-jobs has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 0, math.MaxUint32)
+	case *swf_max_threads == 0 || *swf_max_threads > math.MaxUint32:
+		log.Panicf("swf-max-threads has to be a 32 bit unsigned integer, only values between %d and %d are allowed", 1, math.MaxUint32)
+	case !*sim_processes && !*sim_pages && !*sim_tasks:
 		log.Panic("you must specify at least one simulation to run, run the program with -h for help")
 	}
+	// without a seed every run generates something different, but it can still be repeated with the one we pick here
+	for *seed == 0 {
+		*seed = rand.Uint64()
+	}
+	log.Printf("Generating the simulation input with seed: %d\n\n", *seed)
+	source := func(stream uint64) rand.Source {
+		return rand.NewPCG(*seed, stream)
+	}
 
 	if *sim_processes {
+		roundRobinQuanta := parseQuanta("quanta", *quanta)
+		mlfqQuanta := parseQuanta("mlfq-quanta", *mlfq_quanta)
+		balancer, ok := process.ParseBalancer(*balancing)
+		if !ok {
+			log.Panicf("balancing has to be one of none, push, pull or steal, got: %q", *balancing)
+		}
+		simulationEngine, ok := process.ParseEngine(*engine)
+		if !ok {
+			log.Panicf("engine has to be one of events or ticks, got: %q", *engine)
+		}
+		arrivalDistribution, ok := process.ParseArrivals(*arrivals)
+		if !ok {
+			log.Panicf("arrivals has to be one of uniform or poisson, got: %q", *arrivals)
+		}
+		var burstDistribution process.Distribution
+		switch *burst_distribution {
+		case "uniform":
+			burstDistribution = process.UniformBursts()
+		case "exponential":
+			burstDistribution = process.ExponentialBursts(*burst_mean)
+		case "lognormal":
+			burstDistribution = process.LogNormalBursts(*burst_mean, *burst_sigma)
+		case "pareto":
+			burstDistribution = process.ParetoBursts(*burst_mean, *pareto_shape)
+		case "bimodal":
+			burstDistribution = process.BimodalBursts(*burst_mean, *bimodal_long_mean, *bimodal_long_part)
+		default:
+			log.Panicf("burst-distribution has to be one of uniform, exponential, lognormal, pareto or bimodal, got: %q", *burst_distribution)
+		}
 		log.Printf("Running process simulation with the following parameters:"+
+			"\nprocess-input: %q"+
+			"\nswf-input: %q"+
+			"\nswf-time-scale: %g"+
+			"\nswf-max-jobs: %d"+
+			"\nswf-max-threads: %d"+
 			"\nnum-processes: %d"+
 			"\nmax-arrive-time: %d"+
-			"\nmax-execution-time: %d\n\n",
-			*num_processes, *max_arrive_time, *max_execution_time)
+			"\narrivals: %s"+
+			"\nmax-execution-time: %d"+
+			"\nburst-distribution: %s"+
+			"\nmax-io-bursts: %d"+
+			"\nmax-io-time: %d"+
+			"\nsjf-alpha: %g"+
+			"\nsjf-initial-guess: %g"+
+			"\npriority-levels: %d"+
+			"\naging-rate: %d"+
+			"\nmax-tickets: %d"+
+			"\nlottery-seed: %d"+
+			"\nmax-nice: %d"+
+			"\ncfs-latency: %d"+
+			"\ncfs-granularity: %d"+
+			"\nquanta: %v"+
+			"\nmlfq-quanta: %v"+
+			"\nmlfq-boost: %d"+
+			"\nengine: %s"+
+			"\ncores: %d"+
+			"\nbalancing: %s"+
+			"\nbalance-interval: %d"+
+			"\nmigration-cost: %d"+
+			"\ncontext-switch-cost: %d"+
+			"\naddress-space-cost: %d"+
+			"\naddress-spaces: %d\n\n",
+			*process_input, *swf_input, *swf_time_scale, *swf_max_jobs, *swf_max_threads, *num_processes, *max_arrive_time, arrivalDistribution, *max_execution_time, burstDistribution, *max_io_bursts, *max_io_time, *sjf_alpha, *sjf_initial_guess, *priority_levels, *aging_rate, *max_tickets, *lottery_seed,
+			*max_nice, *cfs_latency, *cfs_granularity, roundRobinQuanta,
+			mlfqQuanta, *mlfq_boost, simulationEngine, *cores, balancer, *balance_interval, *migration_cost,
+			*switch_cost, *address_space_cost, *address_spaces)
+
+		var processes *process.Slice
+		var processDirectory string
+		metadata := flagMetadata()
+		if inputPath := *process_input + *swf_input; inputPath != "" {
+			log.Printf("Loading process simulation input from %s...", inputPath)
+			suffix := "-input"
+			if *swf_input != "" {
+				processes = process.LoadSWF(*swf_input, *swf_time_scale, uint32(*swf_max_jobs), uint32(*swf_max_threads))
+				suffix = fmt.Sprint("-", *swf_time_scale, "-time-scale-swf")
+			} else {
+				processes = process.Load(*process_input)
+			}
+			log.Print("Processes loaded successfully\n\n")
 
-		log.Println("Generating process simulation input...")
-		processes := process.Gen(uint16(*num_processes), uint16(*max_arrive_time), uint16(*max_execution_time))
-		log.Print("Processes generated successfully\n\n")
+			// the loaded processes are saved as deep as the generated ones, named after the file, so that the notebook can plot them too
+			maxArriveTime, maxExecutionTime := processes.MaxTimes()
+			name := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+			processDirectory = fmt.Sprint(len(*processes), "-processes/",
+				maxArriveTime, "-max-arrive-time/",
+				maxExecutionTime, "-max-execution-time-", name, suffix)
+		} else {
+			log.Println("Generating process simulation input...")
+			processes = process.Gen(source(PROCESS_STREAM), process.GenConfig{
+				Num:              uint32(*num_processes),
+				MaxArriveTime:    uint32(*max_arrive_time),
+				Arrivals:         arrivalDistribution,
+				MaxExecutionTime: uint32(*max_execution_time),
+				Bursts:           burstDistribution,
+				PriorityLevels:   uint32(*priority_levels),
+				MaxTickets:       uint32(*max_tickets),
+				MaxNice:          uint8(*max_nice),
+				MaxIOBursts:      uint32(*max_io_bursts),
+				MaxIOTime:        uint32(*max_io_time),
+				AddressSpaces:    uint32(*address_spaces)})
+			log.Print("Processes generated successfully\n\n")
+			// the flags of the distributions that were not chosen are recorded too, so the chosen ones are written out with their parameters
+			metadata.Set("arrival-distribution", arrivalDistribution.String())
+			metadata.Set("cpu-burst-distribution", burstDistribution.String())
 
-		processInputDirectory := fmt.Sprint("in/",
-			*num_processes, "-processes/",
-			*max_arrive_time, "-max-arrive-time/",
-			*max_execution_time, "-max-execution-time")
+			processDirectory = fmt.Sprint(*num_processes, "-processes/",
+				*max_arrive_time, "-max-arrive-time")
+			// the distributions other than the uniform ones are added to the directory level of the limit they draw up to
+			if arrivalDistribution != process.UniformArrivals {
+				processDirectory += fmt.Sprint("-", arrivalDistribution, "-arrivals")
+			}
+			processDirectory += fmt.Sprint("/", *max_execution_time, "-max-execution-time")
+			if *burst_distribution != "uniform" {
+				processDirectory += fmt.Sprint("-", burstDistribution, "-bursts")
+			}
+			// the i/o bursts are kept in the same directory level, so that the results are as deep as the ones without them
+			if *max_io_bursts != 0 {
+				processDirectory += fmt.Sprint("-", *max_io_bursts, "-max-io-bursts-", *max_io_time, "-max-io-time")
+			}
+		}
+		processInputDirectory := "in/" + processDirectory
 
 		log.Println("Saving process simulation input...")
 		Save(processes, processInputDirectory)
 		log.Print("Process simulation input saved to : ../", processInputDirectory, "\n\n")
 
 		log.Println("Running process simulation...")
-		processSimulationResults := process.Sim(processes,
-			process.LCFS,
-			process.PreemptiveLCFS,
-			process.SJF,
-			process.PreemptiveSJF)
+		processAlgNames := []string{"FCFS", "PreemptiveFCFS", "LCFS", "PreemptiveLCFS", "SJF", "PreemptiveSJF",
+			fmt.Sprint("PredictiveSJF-", *sjf_alpha, "-alpha"), fmt.Sprint("PreemptivePredictiveSJF-", *sjf_alpha, "-alpha"),
+			"HRRN", "Priority", "PreemptivePriority", "MLFQ", "CFS"}
+		processPolicies := []process.Policy{
+			process.FCFSPolicy,
+			process.PreemptiveFCFSPolicy,
+			process.LCFSPolicy,
+			process.PreemptiveLCFSPolicy,
+			process.SJFPolicy,
+			process.PreemptiveSJFPolicy,
+			process.PredictiveSJFPolicy(*sjf_alpha, *sjf_initial_guess),
+			process.PreemptivePredictiveSJFPolicy(*sjf_alpha, *sjf_initial_guess),
+			process.HRRNPolicy,
+			process.PriorityPolicy(0),
+			process.PreemptivePriorityPolicy(0),
+			process.MLFQPolicy(mlfqQuanta, uint32(*mlfq_boost)),
+			process.CFSPolicy(uint32(*cfs_latency), uint32(*cfs_granularity))}
+		// the priority schedulers are simulated both with and without aging, so that we can see how it helps with starvation
+		if *aging_rate != 0 {
+			processAlgNames = append(processAlgNames,
+				fmt.Sprint("Priority-", *aging_rate, "-aging-rate"),
+				fmt.Sprint("PreemptivePriority-", *aging_rate, "-aging-rate"))
+			processPolicies = append(processPolicies,
+				process.PriorityPolicy(uint32(*aging_rate)),
+				process.PreemptivePriorityPolicy(uint32(*aging_rate)))
+		}
+		// every quantum gets it's own output directory, so that we can compare how the wait time changes with the quantum
+		// the proportional share schedulers also switch processes every quantum, so they use the same ones as round robin
+		for _, quantum := range roundRobinQuanta {
+			processAlgNames = append(processAlgNames,
+				fmt.Sprint("RoundRobin-", quantum, "-quantum"),
+				fmt.Sprint("Lottery-", quantum, "-quantum"),
+				fmt.Sprint("Stride-", quantum, "-quantum"))
+			processPolicies = append(processPolicies,
+				process.RoundRobinPolicy(quantum),
+				process.LotteryPolicy(quantum, *lottery_seed),
+				process.StridePolicy(quantum))
+		}
+
+		if *cores != 1 {
+			// every algorithm is used as the policy of each core, and saved with the multi-core setup in it's name
+			for i := range processAlgNames {
+				processAlgNames[i] += fmt.Sprint("-", *cores, "-cores-", balancer)
+			}
+		}
+		simulate := func(e process.Engine) ([]*process.Slice, []*process.CoreSlice, []*process.Timeline) {
+			if *cores == 1 {
+				return process.SimUniprocessor(processes, e,
+					uint32(*switch_cost), uint32(*address_space_cost),
+					processPolicies...)
+			}
+			return process.SimSMP(processes, e,
+				uint32(*cores), balancer, uint32(*balance_interval), uint32(*migration_cost),
+				uint32(*switch_cost), uint32(*address_space_cost),
+				processPolicies...)
+		}
+		processSimulationResults, coreSimulationResults, timelines := simulate(simulationEngine)
 		log.Print("Process simulation completed successfully\n\n")
 
+		if *bench {
+			benchEngines(simulate, processSimulationResults, coreSimulationResults, timelines)
+		}
+
+		var overheadSummary strings.Builder
+		for i, alg := range processAlgNames {
+			switches, overhead := coreSimulationResults[i].Overhead()
+			fmt.Fprintf(&overheadSummary, "\n%s: %d context switches, %d units of time of overhead", alg, switches, overhead)
+		}
+		log.Print("Context switch overhead:", overheadSummary.String(), "\n\n")
+
 		log.Println("Saving process simulation results...")
 		save_process_results := func(i int, alg string) {
-			Save(processSimulationResults[i], fmt.Sprint("out/",
-				*num_processes, "-processes/",
-				*max_arrive_time, "-max-arrive-time/",
-				*max_execution_time, "-max-execution-time/",
-				alg))
-		}
-		save_process_results(0, "LCFS")
-		save_process_results(1, "PreemptiveLCFS")
-		save_process_results(2, "SJF")
-		save_process_results(3, "PreemptiveSJF")
-		log.Print("Process simulation results saved to : ../out/",
-			*num_processes, "-processes/",
-			*max_arrive_time, "-max-arrive-time/",
-			*max_execution_time, "-max-execution-time/",
-			"{algorithmName}\n\n",
+			outDir := fmt.Sprint("out/", processDirectory, "/", alg)
+			Save(processSimulationResults[i], outDir)
+			Save(coreSimulationResults[i], outDir)
+			Save(timelines[i], outDir)
+			SaveTrace(timelines[i].Trace(processSimulationResults[i], alg), outDir)
+		}
+		summaries := make(process.SummarySlice, len(processAlgNames))
+		for i, alg := range processAlgNames {
+			save_process_results(i, alg)
+			summaries[i] = process.Summarize(alg, processSimulationResults[i], coreSimulationResults[i])
+		}
+		// the summary compares all the algorithms, so it is saved next to their directories
+		Save(&summaries, "out/"+processDirectory)
+		Save(metadata, "out/"+processDirectory)
+		log.Print("Process simulation summary:\n", Format(&summaries), "\n")
+		log.Print("Process simulation results saved to : ../out/", processDirectory, "/{algorithmName}\n\n",
+			strings.Repeat("-", 80),
+			"\n\n")
+	}
+
+	if *sim_tasks {
+		log.Printf("Running real-time task simulation with the following parameters:"+
+			"\nnum-tasks: %d"+
+			"\nmin-period: %d"+
+			"\nmax-period: %d"+
+			"\nutilization: %g"+
+			"\nconstrained-deadlines: %t"+
+			"\nhorizon: %d\n\n",
+			*num_tasks, *min_period, *max_period, *utilization, *constrained, *horizon)
+
+		log.Println("Generating task simulation input...")
+		tasks := process.GenTasks(source(TASK_STREAM), uint32(*num_tasks), uint32(*min_period), uint32(*max_period), *utilization, *constrained)
+		log.Print("Tasks generated successfully\n\n")
+
+		taskDirectory := fmt.Sprint(*num_tasks, "-tasks/",
+			*utilization, "-utilization/",
+			*min_period, "-", *max_period, "-period")
+		if *constrained {
+			taskDirectory += "/constrained-deadlines"
+		}
+
+		log.Println("Saving task simulation input...")
+		Save(tasks, "in/"+taskDirectory)
+		log.Print("Task simulation input saved to : ../in/", taskDirectory, "\n\n")
+
+		log.Print("Schedulability analysis:\n", process.Analyze(tasks), "\n\n")
+
+		log.Println("Running task simulation...")
+		taskAlgNames := []string{"EDF", "RM"}
+		taskSimulationResults := process.SimTasks(tasks, uint32(*horizon), process.EDF, process.RM)
+		log.Print("Task simulation completed successfully\n\n")
+
+		log.Println("Saving task simulation results...")
+		for i, alg := range taskAlgNames {
+			Save(taskSimulationResults[i], fmt.Sprint("out/", taskDirectory, "/", alg))
+		}
+		Save(flagMetadata(), "out/"+taskDirectory)
+		log.Print("Task simulation results saved to : ../out/", taskDirectory, "/{algorithmName}\n\n",
 			strings.Repeat("-", 80),
 			"\n\n")
 	}
 
 	if *sim_pages {
+		var pageGenerator page.Generator
+		switch *page_generator {
+		case "normal":
+			pageGenerator = page.NormalGenerator()
+		case "locality":
+			pageGenerator = page.LocalityGenerator(uint32(*working_set_size), uint32(*phase_length), *phase_transition, *sequential_refs, *loop_refs)
+		default:
+			log.Panicf("page-generator has to be one of normal or locality, got: %q", *page_generator)
+		}
 		log.Printf("Running page simulation with the following parameters:"+
+			"\npage-input: %q"+
 			"\nnum-pages: %d"+
-			"\ntotal-refs: %d\n\n",
-			*num_pages, *total_refs)
-
-		log.Println("Generating page simulation input and running simulation...")
-		referencePattern, pageSimulationResults := page.Sim(uint16(*num_pages), uint16(*total_refs),
-			page.FIFO,
-			page.LFU,
-			page.PersistentFrequencyLFU)
+			"\ntotal-refs: %d"+
+			"\npage-generator: %s"+
+			"\naging-bits: %d\n\n",
+			*page_input, *num_pages, *total_refs, pageGenerator, *aging_bits)
+
+		pageAlgNames := []string{"FIFO", "LFU", "PersistentFrequencyLFU", "LRU", fmt.Sprint("Aging-", *aging_bits, "-bits")}
+		pageAlgs := []page.Alg{page.FIFO, page.LFU, page.PersistentFrequencyLFU, page.LRU, page.Aging(uint8(*aging_bits))}
+		var referencePattern []uint32
+		var pageSimulationResults []*page.Slice
+		var pageDirectory string
+		metadata := flagMetadata()
+		if *page_input != "" {
+			log.Printf("Loading page simulation input from %s and running simulation...", *page_input)
+			referencePattern = page.LoadReferencePattern(*page_input)
+			pageSimulationResults = page.SimReferencePattern(referencePattern, pageAlgs...)
+
+			// the pages are numbered from 0, so the highest referenced one tells us how many there are
+			numPages := slices.Max(referencePattern) + 1
+			name := strings.TrimSuffix(filepath.Base(*page_input), filepath.Ext(*page_input))
+			pageDirectory = fmt.Sprint(numPages, "-pages/",
+				len(referencePattern), "-refs-", name, "-input")
+		} else {
+			log.Println("Generating page simulation input and running simulation...")
+			referencePattern, pageSimulationResults = page.Sim(source(PAGE_STREAM), pageGenerator, uint32(*num_pages), uint32(*total_refs), pageAlgs...)
+			metadata.Set("page-reference-generator", pageGenerator.String())
+
+			pageDirectory = fmt.Sprint(*num_pages, "-pages/",
+				*total_refs, "-refs")
+			// the normal generator keeps the old directory names, so that the earlier results are still next to the new ones
+			if *page_generator != "normal" {
+				pageDirectory += fmt.Sprint("-", pageGenerator)
+			}
+		}
 		log.Print("Page simulation completed successfully\n\n")
 
 		log.Println("Saving page simulation input...")
-		page.SaveReferencePattern(referencePattern, fmt.Sprint("in/",
-			*num_pages, "-pages/",
-			*total_refs, "-refs"))
-		log.Print("Page simulation input saved to : ../in/",
-			*num_pages, "-pages/",
-			*total_refs, "-refs\n\n")
+		page.SaveReferencePattern(referencePattern, "in/"+pageDirectory)
+		log.Print("Page simulation input saved to : ../in/", pageDirectory, "\n\n")
 
 		log.Println("Saving page simulation results...")
 		save_page_results := func(i int, alg string) {
-			Save(pageSimulationResults[i], fmt.Sprint("out/",
-				*num_pages, "-pages/",
-				*total_refs, "-refs/",
-				alg))
-		}
-		save_page_results(0, "FIFO")
-		save_page_results(1, "LFU")
-		save_page_results(2, "PersistentFrequencyLFU")
-		log.Print("Page simulation results saved to : ../out/",
-			*num_pages, "-pages/",
-			*total_refs, "-refs/",
-			"{algorithmName}\n\n",
+			outDir := fmt.Sprint("out/", pageDirectory, "/", alg)
+			Save(pageSimulationResults[i], outDir)
+			SaveTrace(pageSimulationResults[i].Trace(alg), outDir)
+		}
+		for i, alg := range pageAlgNames {
+			save_page_results(i, alg)
+		}
+		Save(metadata, "out/"+pageDirectory)
+		log.Print("Page simulation results saved to : ../out/", pageDirectory, "/{algorithmName}\n\n",
 			strings.Repeat("-", 80),
 			"\n\n")
 	}
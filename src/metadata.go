@@ -0,0 +1,37 @@
+package main
+
+import "flag"
+
+// Metadata is a Recorder of the parameters a simulation was run with, it's saved next to the results,
+// so that we can tell what they came from, and run the same simulation again
+type Metadata struct {
+	params [][]string
+}
+
+// flagMetadata returns the values of all the command line flags, the ones left at their default included
+func flagMetadata() *Metadata {
+	var m Metadata
+	flag.VisitAll(func(f *flag.Flag) {
+		m.params = append(m.params, []string{f.Name, f.Value.String()})
+	})
+	return &m
+}
+
+// Set records a parameter that is not a flag, or replaces the value of one with what it ended up being
+func (m *Metadata) Set(name, value string) {
+	for _, param := range m.params {
+		if param[0] == name {
+			param[1] = value
+			return
+		}
+	}
+	m.params = append(m.params, []string{name, value})
+}
+
+func (m *Metadata) Name() string {
+	return "metadata"
+}
+
+func (m *Metadata) Records() [][]string {
+	return append([][]string{{"parameter", "value"}}, m.params...)
+}
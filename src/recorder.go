@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"reflect"
+	"src/sim/trace"
 	"strings"
 	"text/tabwriter"
 )
@@ -15,6 +16,18 @@ type Recorder interface {
 	Records() [][]string
 }
 
+// Namer can be implemented by a Recorder to choose the name of it's output files,
+// for when more than one Recorder from the same package is saved in the same directory
+type Namer interface {
+	Name() string
+}
+
+// Renderer can be implemented by a Recorder to replace the tab aligned table in it's txt file,
+// with a rendering that is easier to read, like a chart
+type Renderer interface {
+	Render() string
+}
+
 // writeRecords writes the records of the passed in Recorder to the output io.Writer encoded as csv,
 // or with tab alignment if the io.Writer is a tabwriter.Writer
 func writeRecords(r Recorder, output io.Writer) {
@@ -28,7 +41,19 @@ func writeRecords(r Recorder, output io.Writer) {
 	}
 }
 
-func Save(r Recorder, outDir string) {
+// Format returns the records of the passed in Recorder as tab aligned columns, for printing them to the log
+func Format(r Recorder) string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 4, 3, ' ', 0)
+	writeRecords(r, tw)
+	if err := tw.Flush(); err != nil {
+		log.Panic(err)
+	}
+	return b.String()
+}
+
+// makeOutDir creates the output directory, relative to the parent directory, and returns it's path ending with a '/'
+func makeOutDir(outDir string) string {
 	if outDir == "" {
 		log.Panic("you must provide the path for the output directory")
 	}
@@ -40,9 +65,27 @@ func Save(r Recorder, outDir string) {
 	if err := os.MkdirAll(outDirPath, 0775); err != nil {
 		log.Panic(err)
 	}
-	// here we name the output files with the name of the package they come from
+	return outDirPath
+}
+
+// SaveTrace writes the trace to trace.json in the output directory, so that it can be opened in Perfetto or chrome://tracing
+func SaveTrace(t *trace.Trace, outDir string) {
+	jsonFile, err := os.Create(makeOutDir(outDir) + "trace.json")
+	defer jsonFile.Close()
+	if err != nil {
+		log.Panic(err)
+	}
+	t.Write(jsonFile)
+}
+
+func Save(r Recorder, outDir string) {
+	outDirPath := makeOutDir(outDir)
+	// here we name the output files with the name of the package they come from, unless the Recorder chooses it's own name
 	rpath := reflect.Indirect(reflect.ValueOf(r)).Type().PkgPath()
 	rname := rpath[strings.LastIndexByte(rpath, '/')+1:]
+	if namer, ok := r.(Namer); ok {
+		rname = namer.Name()
+	}
 	filename := outDirPath + rname
 
 	// we write 2 files, one is  csv file for easier work with python,
@@ -59,6 +102,12 @@ func Save(r Recorder, outDir string) {
 	if err != nil {
 		log.Panic(err)
 	}
+	if renderer, ok := r.(Renderer); ok {
+		if _, err = io.WriteString(textFile, renderer.Render()); err != nil {
+			log.Panic(err)
+		}
+		return
+	}
 	tw := tabwriter.NewWriter(textFile, 0, 4, 3, ' ', 0)
 	writeRecords(r, tw)
 	if err = tw.Flush(); err != nil {
@@ -1,7 +1,6 @@
 package sim
 
 import (
-	"container/ring"
 	"log"
 )
 
@@ -68,8 +67,20 @@ func (s *Stack[T]) Empty() bool {
 	return len(s.items) == 0
 }
 
+func (s *Stack[T]) Len() int {
+	if s == nil {
+		log.Panic("pointer to stack cannot be nil")
+	}
+	if s.items == nil {
+		log.Panic("The stack's underlying slice should never be nil,",
+			"\ncreate the stack with the ds.NewStack() function and not new")
+	}
+
+	return len(s.items)
+}
+
 type Queue[T any] struct {
-	ring *ring.Ring
+	items []T
 }
 
 // NewQueue returns a pointer to an initialized queue, only values greater than 0 are allowed for len
@@ -78,31 +89,39 @@ func NewQueue[T any](len int) *Queue[T] {
 		log.Panic("A 0 length Queue is nil, initialize with a length")
 	}
 
-	return &Queue[T]{ring.New(len)}
+	return &Queue[T]{make([]T, 0, len)}
 }
 
 func (q *Queue[T]) Push(val T) {
 	if q == nil {
 		log.Panic("pointer to queue cannot be nil")
 	}
-	if q.ring == nil {
-		log.Panic("The queue's underlying ring should never be nil,",
+	if q.items == nil {
+		log.Panic("The queue's underlying slice should never be nil,",
 			"\ncreate the queue with the ds.NewQueue() function and not new")
 	}
 
-	q.ring = q.ring.Link(&ring.Ring{Value: val})
+	q.items = append(q.items, val)
 }
 
 func (q *Queue[T]) Pop() (val T) {
 	if q == nil {
 		log.Panic("pointer to queue cannot be nil")
 	}
-	if q.ring == nil {
-		log.Panic("The queue's underlying ring should never be nil,",
+	if q.items == nil {
+		log.Panic("The queue's underlying slice should never be nil,",
 			"\ncreate the queue with the ds.NewQueue() function and not new")
 	}
+	if len(q.items) == 0 {
+		log.Panic("cannot pop from an empty queue")
+	}
 
-	return q.ring.Unlink(1).Value.(T)
+	val = q.items[0]
+	// we clear the popped slot so the queue does not keep the value alive through the underlying array
+	var zero T
+	q.items[0] = zero
+	q.items = q.items[1:]
+	return val
 }
 
 // Front is a convenience function to get the value of the first element of the queue without the need to pop
@@ -110,22 +129,37 @@ func (q *Queue[T]) Front() (val T) {
 	if q == nil {
 		log.Panic("pointer to queue cannot be nil")
 	}
-	if q.ring == nil {
-		log.Panic("The queue's underlying ring should never be nil,",
+	if q.items == nil {
+		log.Panic("The queue's underlying slice should never be nil,",
 			"\ncreate the queue with the ds.NewQueue() function and not new")
 	}
+	if len(q.items) == 0 {
+		log.Panic("cannot get the front of an empty queue")
+	}
 
-	return q.ring.Value.(T)
+	return q.items[0]
 }
 
 func (q *Queue[T]) Empty() bool {
 	if q == nil {
 		log.Panic("pointer to queue cannot be nil")
 	}
-	if q.ring == nil {
-		log.Panic("The queue's underlying ring should never be nil,",
+	if q.items == nil {
+		log.Panic("The queue's underlying slice should never be nil,",
+			"\ncreate the queue with the ds.NewQueue() function and not new")
+	}
+
+	return len(q.items) == 0
+}
+
+func (q *Queue[T]) Len() int {
+	if q == nil {
+		log.Panic("pointer to queue cannot be nil")
+	}
+	if q.items == nil {
+		log.Panic("The queue's underlying slice should never be nil,",
 			"\ncreate the queue with the ds.NewQueue() function and not new")
 	}
 
-	return q.ring.Len() == 0
+	return len(q.items)
 }
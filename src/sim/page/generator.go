@@ -0,0 +1,96 @@
+package page
+
+import (
+	"fmt"
+	"log"
+	"math/rand/v2"
+)
+
+// Generator generates the reference patterns that are simulated by Sim
+type Generator interface {
+	fmt.Stringer
+	// gen returns a reference pattern of len references to numPages pages, drawn with rng
+	gen(rng *rand.Rand, numPages, len uint32) []uint32
+}
+
+type normalGenerator struct{}
+
+// NormalGenerator draws every reference separately from a normal distribution over the pages,
+// so some pages are referenced more than others, but there is no locality in time
+func NormalGenerator() Generator { return normalGenerator{} }
+
+func (normalGenerator) String() string { return "normal" }
+func (normalGenerator) gen(rng *rand.Rand, numPages, len uint32) []uint32 {
+	return genNormal(rng, numPages, len)
+}
+
+type localityGenerator struct {
+	workingSetSize        uint32
+	phaseLength           uint32
+	transitionProbability float64
+	sequential            float64
+	loop                  float64
+}
+
+// LocalityGenerator generates reference patterns that go through phases, like a program moving from one part of it's code and data to another,
+// every phase references a working set of workingSetSize consecutive pages for phaseLength references,
+// after which it moves to a new working set with transitionProbability, or stays in the same one,
+// a reference is sequential with the probability given by sequential, it goes on from the last sequential reference to the next page,
+// past the end of the working set too, like a program reading through an array, loop with the probability given by loop,
+// it goes through the working set in order over and over again, and the rest of the references are to random pages of the working set
+func LocalityGenerator(workingSetSize, phaseLength uint32, transitionProbability, sequential, loop float64) Generator {
+	if workingSetSize == 0 {
+		log.Panic("The working set has to have at least one page")
+	}
+	if phaseLength == 0 {
+		log.Panic("The phases have to be at least one reference long")
+	}
+	if transitionProbability < 0 || transitionProbability > 1 {
+		log.Panic("The phase transition probability has to be between 0 and 1")
+	}
+	if sequential < 0 || loop < 0 || sequential+loop > 1 {
+		log.Panic("The sequential and loop fractions of the references cannot be negative, or add up to more than 1")
+	}
+	return localityGenerator{workingSetSize, phaseLength, transitionProbability, sequential, loop}
+}
+
+func (l localityGenerator) String() string {
+	return fmt.Sprint("locality-", l.workingSetSize, "-working-set-", l.phaseLength, "-phase-", l.transitionProbability, "-transition-",
+		l.sequential, "-sequential-", l.loop, "-loop")
+}
+
+func (l localityGenerator) gen(rng *rand.Rand, numPages, len uint32) (referencePattern []uint32) {
+	if len == 0 {
+		log.Panic("The page reference pattern length must be greater than zero")
+	}
+	if numPages == 0 {
+		log.Panic("The number of pages to simulate must be larger than zero")
+	}
+	if l.workingSetSize > numPages {
+		log.Panicf("The working set cannot be bigger than the %d pages", numPages)
+	}
+
+	referencePattern = make([]uint32, len)
+	// the first page of the working set, it's pages are the ones from here on, and it never goes past the last page
+	var base uint32
+	// the next pages of the sequential and loop references, they start over from the first page of every new working set
+	var scan, loop uint32
+	for i := range referencePattern {
+		if i == 0 || (uint32(i)%l.phaseLength == 0 && rng.Float64() < l.transitionProbability) {
+			base = uint32(rng.UintN(uint(numPages-l.workingSetSize) + 1))
+			scan, loop = base, 0
+		}
+
+		switch r := rng.Float64(); {
+		case r < l.sequential:
+			referencePattern[i] = scan
+			scan = (scan + 1) % numPages
+		case r < l.sequential+l.loop:
+			referencePattern[i] = base + loop
+			loop = (loop + 1) % l.workingSetSize
+		default:
+			referencePattern[i] = base + uint32(rng.UintN(uint(l.workingSetSize)))
+		}
+	}
+	return referencePattern
+}
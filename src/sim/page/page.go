@@ -8,17 +8,22 @@ import (
 	"math/rand/v2"
 	"os"
 	"reflect"
+	"src/sim/trace"
+	"strconv"
+	"strings"
+	"unicode"
 )
 
 type Page struct {
-	id           uint16
-	timesUsed    uint16
-	pageFaultAt  []uint16
-	swappedOutAt []uint16
+	id           uint32
+	timesUsed    uint32
+	pageFaultAt  []uint32
+	swappedOutAt []uint32
 }
 
-// gen generates a random pattern of referencing a given amount of pages a given number of times
-func gen(numPages, len uint16) (referencePattern []uint16) {
+// genNormal generates a random pattern of referencing a given amount of pages a given number of times, with random numbers from rng,
+// every reference is drawn separately from a normal distribution over the pages
+func genNormal(rng *rand.Rand, numPages, len uint32) (referencePattern []uint32) {
 	if len == 0 {
 		log.Panic("The page reference pattern length must be greater than zero")
 	}
@@ -26,7 +31,7 @@ func gen(numPages, len uint16) (referencePattern []uint16) {
 		log.Panic("The number of pages to simulate must be larger than zero")
 	}
 
-	referencePattern = make([]uint16, len)
+	referencePattern = make([]uint32, len)
 	// we use a normal distribution, because using a uniform distribution will not show a difference between the algorithms
 	// because they will be used the same number of times on average
 	mean := float64(numPages) / 2
@@ -37,14 +42,15 @@ func gen(numPages, len uint16) (referencePattern []uint16) {
 	for i := range referencePattern {
 		// we clamp the values to the range of the number of pages, so that in the rare case that the value falls outside
 		// 3 standard deviations, we will still get a valid value
-		// we subtract the smallest non-zero float64 to avoid the case where the value is converted to 64, which would be the 65'th page
-		referencePattern[i] = uint16(math.Max(0, math.Min(float64(numPages)-math.SmallestNonzeroFloat64, rand.NormFloat64()*stdDev+mean)))
+		// we clamp to the float64 right below numPages to avoid the case where the value is converted to 64, which would be the 65'th page,
+		// subtracting the smallest non-zero float64 is not enough for that, because the result rounds back to numPages
+		referencePattern[i] = uint32(math.Max(0, math.Min(math.Nextafter(float64(numPages), 0), rng.NormFloat64()*stdDev+mean)))
 	}
 	return referencePattern
 }
 
 // SaveReferencePattern saves the reference pattern to an output file in a .csv format
-func SaveReferencePattern(referencePattern []uint16, outDir string) {
+func SaveReferencePattern(referencePattern []uint32, outDir string) {
 	if referencePattern == nil {
 		log.Panic("The reference pattern slice cannot be nil")
 	}
@@ -81,6 +87,35 @@ func SaveReferencePattern(referencePattern []uint16, outDir string) {
 	w.Flush()
 }
 
+// LoadReferencePattern reads a reference pattern from the file at path, either a pageReferencePattern.csv saved by SaveReferencePattern,
+// or a trace with the referenced pages separated by spaces or new lines
+func LoadReferencePattern(path string) (referencePattern []uint32) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	fields := strings.FieldsFunc(string(content), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	if len(fields) == 0 {
+		log.Panicf("%s: the reference pattern has to contain something", path)
+	}
+	if len(fields) > math.MaxUint32 {
+		log.Panicf("%s: the reference pattern length cannot exceed %d", path, math.MaxUint32)
+	}
+	referencePattern = make([]uint32, len(fields))
+	for i, field := range fields {
+		page, err := strconv.ParseUint(field, 10, 32)
+		// the pages are numbered from 0, so the largest page number is left out for the number of pages to fit in 32 bits too
+		if err != nil || page == math.MaxUint32 {
+			log.Panicf("%s: reference %d has to be a page number between 0 and %d, got: %q", path, i+1, uint32(math.MaxUint32-1), field)
+		}
+		referencePattern[i] = uint32(page)
+	}
+	return referencePattern
+}
+
 type Slice []Page
 
 var pageNumFields = reflect.TypeOf(Page{}).NumField()
@@ -113,6 +148,28 @@ func (pages *Slice) Records() (records [][]string) {
 	return records
 }
 
+// Trace converts the results of a page simulation into a Chrome trace named name, every reference to a page is one unit of time,
+// the page faults and swap outs are instant events on the track of the memory
+func (pages *Slice) Trace(name string) *trace.Trace {
+	if pages == nil || len(*pages) == 0 {
+		log.Panic("The page slice to trace cannot be nil or empty")
+	}
+
+	res := trace.New()
+	res.NameProcess(0, name)
+	res.NameTrack(0, 0, "memory")
+	for _, page := range *pages {
+		args := map[string]any{"page": page.id}
+		for _, at := range page.pageFaultAt {
+			res.Instant(fmt.Sprint("page ", page.id, " fault"), "page fault", 0, 0, uint64(at), trace.THREAD_SCOPE, args)
+		}
+		for _, at := range page.swappedOutAt {
+			res.Instant(fmt.Sprint("page ", page.id, " swapped out"), "swap out", 0, 0, uint64(at), trace.THREAD_SCOPE, args)
+		}
+	}
+	return res
+}
+
 // Heap implements the container.Heap.Interface to get a page min Heap sorted by least times used (for LFU)
 type Heap []*Page
 
@@ -0,0 +1,63 @@
+package page
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTrace writes content to a file in a temporary directory, and returns it's path
+func writeTrace(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "trace.txt")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadReferencePattern(t *testing.T) {
+	want := []uint32{3, 1, 4, 1, 5, 9, 2, 6}
+	tests := map[string]string{
+		"saved by SaveReferencePattern": "3,1,4,1,5,9,2,6\n",
+		"separated by spaces":           "3 1 4 1 5 9 2 6",
+		"one on every line":             "3\n1\n4\n1\n5\n9\n2\n6\n",
+		"mixed separators":              "  3, 1\t4\r\n1 ,5,,9\n\n2 6  \n",
+	}
+	for name, content := range tests {
+		if got := LoadReferencePattern(writeTrace(t, content)); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: loaded %v, want %v", name, got, want)
+		}
+	}
+
+	// the largest page number that still leaves room for the number of pages in 32 bits
+	if got := LoadReferencePattern(writeTrace(t, "0 4294967294")); !reflect.DeepEqual(got, []uint32{0, 4294967294}) {
+		t.Errorf("loaded %v, want the largest page number", got)
+	}
+}
+
+func TestLoadReferencePatternRejectsInvalidTraces(t *testing.T) {
+	tests := map[string]struct{ content, want string }{
+		"empty file":            {"", "has to contain something"},
+		"only separators":       {" ,\n\t, \n", "has to contain something"},
+		"MaxUint32":             {"1 2 4294967295", "reference 3 has to be a page number"},
+		"too large for 32 bits": {"4294967296", "reference 1 has to be a page number"},
+		"negative page":         {"1,-2", "reference 2 has to be a page number"},
+		"not a number":          {"1 two 3", "reference 2 has to be a page number"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Error("loading the trace did not panic")
+				} else if msg, _ := r.(string); !strings.Contains(msg, test.want) {
+					t.Errorf("loading the trace panicked with %q, want it to mention %q", r, test.want)
+				}
+			}()
+			LoadReferencePattern(writeTrace(t, test.content))
+		})
+	}
+}
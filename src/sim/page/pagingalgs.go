@@ -1,19 +1,23 @@
 package page
 
 import (
+	"cmp"
 	"container/heap"
+	"container/list"
 	"log"
 	"maps"
 	"math"
+	"math/rand/v2"
+	"slices"
 	"src/sim"
 )
 
 const FRAME_SIZE = 16
 
-type Alg func(referencePattern []uint16) *Slice
+type Alg func(referencePattern []uint32) *Slice
 
-// Sim runs a simulation of a randomly generated reference pattern using the strategies in the alg slice
-func Sim(numPages, referencePatternLen uint16, algs ...Alg) (referencePattern []uint16, res []*Slice) {
+// Sim runs a simulation of a reference pattern generated by generator with random numbers from src, using the strategies in the alg slice
+func Sim(src rand.Source, generator Generator, numPages, referencePatternLen uint32, algs ...Alg) (referencePattern []uint32, res []*Slice) {
 	if numPages == 0 {
 		log.Panic("The number of pages to simulate cannot be zero")
 	}
@@ -27,43 +31,45 @@ func Sim(numPages, referencePatternLen uint16, algs ...Alg) (referencePattern []
 		log.Panic("The number of algorithms to simulate cannot be zero")
 	}
 
-	referencePattern = gen(numPages, referencePatternLen)
+	referencePattern = generator.gen(rand.New(src), numPages, referencePatternLen)
+	return referencePattern, SimReferencePattern(referencePattern, algs...)
+}
+
+// SimReferencePattern runs a simulation of the given reference pattern using the strategies in the alg slice,
+// for reference patterns that were not generated, like ones loaded with LoadReferencePattern
+func SimReferencePattern(referencePattern []uint32, algs ...Alg) (res []*Slice) {
+	if len(referencePattern) == 0 {
+		log.Panic("The reference pattern has to contain something")
+	}
+	if len(algs) == 0 {
+		log.Panic("The number of algorithms to simulate cannot be zero")
+	}
+
 	res = make([]*Slice, len(algs))
 	for i, alg := range algs {
 		res[i] = alg(referencePattern)
 	}
-	return referencePattern, res
+	return res
 }
 
-func FIFO(referencePattern []uint16) *Slice {
+func FIFO(referencePattern []uint32) *Slice {
 	if referencePattern == nil {
 		log.Panic("The reference pattern slice cannot be nil")
 	}
 	if len(referencePattern) == 0 {
 		log.Panic("The reference pattern has to contain something")
 	}
-	if len(referencePattern) > math.MaxUint16 {
-		log.Panicf("The reference pattern length cannot exceed %d", math.MaxUint16)
+	if len(referencePattern) > math.MaxUint32 {
+		log.Panicf("The reference pattern length cannot exceed %d", math.MaxUint32)
 	}
 
 	// the page table stores whether a page is in memory
-	pageTable := make(map[uint16]bool)
-	memory := make(map[uint16]*Page)
-	swap := make(map[uint16]*Page)
+	pageTable := make(map[uint32]bool)
+	memory := make(map[uint32]*Page)
+	swap := newSwap(referencePattern)
 	// the delete queue stores the indices of pages that are in memory in the order they were referenced
 	// this is perfect for implementing fifo
-	deleteQueue := sim.NewQueue[uint16](FRAME_SIZE)
-
-	for _, page := range referencePattern {
-		pageTable[page] = false
-	}
-	for page := range maps.Keys(pageTable) {
-		swap[page] = &Page{
-			page,
-			0,
-			make([]uint16, 0, len(referencePattern)),
-			make([]uint16, 0, len(referencePattern))}
-	}
+	deleteQueue := sim.NewQueue[uint32](FRAME_SIZE)
 
 	for i, page := range referencePattern {
 		if inMemory := pageTable[page]; !inMemory {
@@ -72,59 +78,41 @@ func FIFO(referencePattern []uint16) *Slice {
 				victimPage := deleteQueue.Pop()
 				swap[victimPage] = memory[victimPage]
 				delete(memory, victimPage)
-				swap[victimPage].swappedOutAt = append(swap[victimPage].swappedOutAt, uint16(i))
+				swap[victimPage].swappedOutAt = append(swap[victimPage].swappedOutAt, uint32(i))
 				pageTable[victimPage] = false
 			}
 
 			memory[page] = swap[page]
 			delete(swap, page)
 			pageTable[page] = true
-			memory[page].pageFaultAt = append(memory[page].pageFaultAt, uint16(i))
+			memory[page].pageFaultAt = append(memory[page].pageFaultAt, uint32(i))
 			deleteQueue.Push(page)
 			continue
 		}
 	}
-	res := Slice(make([]Page, 0, len(pageTable)))
-	for page := range maps.Values(swap) {
-		res = append(res, *page)
-	}
-	for page := range maps.Values(memory) {
-		res = append(res, *page)
-	}
-	return &res
+	return collect(swap, memory)
 }
 
-func LFU(referencePattern []uint16) *Slice {
+func LFU(referencePattern []uint32) *Slice {
 	if referencePattern == nil {
 		log.Panic("The reference pattern slice cannot be nil")
 	}
 	if len(referencePattern) == 0 {
 		log.Panic("The reference pattern has to contain something")
 	}
-	if len(referencePattern) > math.MaxUint16 {
-		log.Panicf("The reference pattern length cannot exceed %d", math.MaxUint16)
+	if len(referencePattern) > math.MaxUint32 {
+		log.Panicf("The reference pattern length cannot exceed %d", math.MaxUint32)
 	}
 
 	// the page table stores whether a page is in memory
-	pageTable := make(map[uint16]bool)
-	memory := make(map[uint16]*Page)
-	swap := make(map[uint16]*Page)
+	pageTable := make(map[uint32]bool)
+	memory := make(map[uint32]*Page)
+	swap := newSwap(referencePattern)
 	// this heap stores pages that are in memory, sorted by the amount of times they have been used since getting swapped into memory
 	// a heap is used because heapify has better time complexity than sort, and we only need the smallest element
 	deleteHeap := new(Heap)
 	*deleteHeap = make([]*Page, 0, FRAME_SIZE)
 
-	for _, page := range referencePattern {
-		pageTable[page] = false
-	}
-	for page := range maps.Keys(pageTable) {
-		swap[page] = &Page{
-			page,
-			0,
-			make([]uint16, 0, len(referencePattern)),
-			make([]uint16, 0, len(referencePattern))}
-	}
-
 	for i, page := range referencePattern {
 		if inMemory := pageTable[page]; !inMemory {
 			// if there is no space left in memory we need to move a page to swap
@@ -134,7 +122,7 @@ func LFU(referencePattern []uint16) *Slice {
 				victimPage := heap.Pop(deleteHeap).(*Page)
 				delete(memory, victimPage.id)
 				swap[victimPage.id] = victimPage
-				victimPage.swappedOutAt = append(victimPage.swappedOutAt, uint16(i))
+				victimPage.swappedOutAt = append(victimPage.swappedOutAt, uint32(i))
 				// whenever a page is swapped out it's counter is reset, so that the algorithm responds to locality changes better
 				victimPage.timesUsed = 0
 				pageTable[victimPage.id] = false
@@ -144,53 +132,35 @@ func LFU(referencePattern []uint16) *Slice {
 			heap.Push(deleteHeap, memory[page])
 			delete(swap, page)
 			pageTable[page] = true
-			memory[page].pageFaultAt = append(memory[page].pageFaultAt, uint16(i))
+			memory[page].pageFaultAt = append(memory[page].pageFaultAt, uint32(i))
 		}
 		// if the page was already in memory we just increment the amount of times it was used
 		memory[page].timesUsed++
 	}
-	res := Slice(make([]Page, 0, len(pageTable)))
-	for page := range maps.Values(swap) {
-		res = append(res, *page)
-	}
-	for page := range maps.Values(memory) {
-		res = append(res, *page)
-	}
-	return &res
+	return collect(swap, memory)
 }
 
 // PersistentFrequencyLFU implements LFU without resetting the use frequency counter when unloading a page from memory
-func PersistentFrequencyLFU(referencePattern []uint16) *Slice {
+func PersistentFrequencyLFU(referencePattern []uint32) *Slice {
 	if referencePattern == nil {
 		log.Panic("The reference pattern slice cannot be nil")
 	}
 	if len(referencePattern) == 0 {
 		log.Panic("The reference pattern has to contain something")
 	}
-	if len(referencePattern) > math.MaxUint16 {
-		log.Panicf("The reference pattern length cannot exceed %d", math.MaxUint16)
+	if len(referencePattern) > math.MaxUint32 {
+		log.Panicf("The reference pattern length cannot exceed %d", math.MaxUint32)
 	}
 
 	// the page table stores whether a page is in memory
-	pageTable := make(map[uint16]bool)
-	memory := make(map[uint16]*Page)
-	swap := make(map[uint16]*Page)
+	pageTable := make(map[uint32]bool)
+	memory := make(map[uint32]*Page)
+	swap := newSwap(referencePattern)
 	// this heap stores pages that are in memory, sorted by the amount of times they have been used since getting swapped into memory
 	// a heap is used because heapify has better time complexity than sort, and we only need the smallest element
 	deleteHeap := new(Heap)
 	*deleteHeap = make([]*Page, 0, FRAME_SIZE)
 
-	for _, page := range referencePattern {
-		pageTable[page] = false
-	}
-	for page := range maps.Keys(pageTable) {
-		swap[page] = &Page{
-			page,
-			0,
-			make([]uint16, 0, len(referencePattern)),
-			make([]uint16, 0, len(referencePattern))}
-	}
-
 	for i, page := range referencePattern {
 		if inMemory := pageTable[page]; !inMemory {
 			// if there is no space left in memory we need to move a page to swap
@@ -200,7 +170,7 @@ func PersistentFrequencyLFU(referencePattern []uint16) *Slice {
 				victimPage := heap.Pop(deleteHeap).(*Page)
 				delete(memory, victimPage.id)
 				swap[victimPage.id] = victimPage
-				victimPage.swappedOutAt = append(victimPage.swappedOutAt, uint16(i))
+				victimPage.swappedOutAt = append(victimPage.swappedOutAt, uint32(i))
 				pageTable[victimPage.id] = false
 			}
 
@@ -208,17 +178,146 @@ func PersistentFrequencyLFU(referencePattern []uint16) *Slice {
 			heap.Push(deleteHeap, memory[page])
 			delete(swap, page)
 			pageTable[page] = true
-			memory[page].pageFaultAt = append(memory[page].pageFaultAt, uint16(i))
+			memory[page].pageFaultAt = append(memory[page].pageFaultAt, uint32(i))
 		}
 		// if the page was already in memory we just increment the amount of times it was used
 		memory[page].timesUsed++
 	}
-	res := Slice(make([]Page, 0, len(pageTable)))
+	return collect(swap, memory)
+}
+
+// LRU swaps out the page that was referenced the longest time ago, the pages in memory are kept in a list
+// from the least to the most recently referenced one, and the map finds a page in it, so that every reference is O(1)
+func LRU(referencePattern []uint32) *Slice {
+	if referencePattern == nil {
+		log.Panic("The reference pattern slice cannot be nil")
+	}
+	if len(referencePattern) == 0 {
+		log.Panic("The reference pattern has to contain something")
+	}
+	if len(referencePattern) > math.MaxUint32 {
+		log.Panicf("The reference pattern length cannot exceed %d", math.MaxUint32)
+	}
+
+	memory := make(map[uint32]*Page)
+	swap := newSwap(referencePattern)
+	// the front of the list is the least recently referenced page, and elements stores where every page in memory is in it
+	recency := list.New()
+	elements := make(map[uint32]*list.Element, FRAME_SIZE)
+
+	for i, page := range referencePattern {
+		if element, inMemory := elements[page]; inMemory {
+			recency.MoveToBack(element)
+			continue
+		}
+		// if there is no space left in memory we need to move a page to swap
+		if recency.Len() == FRAME_SIZE {
+			victimPage := recency.Remove(recency.Front()).(uint32)
+			delete(elements, victimPage)
+			swap[victimPage] = memory[victimPage]
+			delete(memory, victimPage)
+			swap[victimPage].swappedOutAt = append(swap[victimPage].swappedOutAt, uint32(i))
+		}
+
+		memory[page] = swap[page]
+		delete(swap, page)
+		memory[page].pageFaultAt = append(memory[page].pageFaultAt, uint32(i))
+		elements[page] = recency.PushBack(page)
+	}
+	return collect(swap, memory)
+}
+
+// Aging approximates LRU with a counter of the given number of bits for every page in memory, that is shifted right on every reference,
+// with the highest bit set for the referenced page, and swaps out the page with the lowest counter, the wider the counters, the closer it gets to LRU
+func Aging(bits uint8) Alg {
+	if bits == 0 || bits > 64 {
+		log.Panic("The aging counters have to be between 1 and 64 bits wide")
+	}
+	return func(referencePattern []uint32) *Slice {
+		if referencePattern == nil {
+			log.Panic("The reference pattern slice cannot be nil")
+		}
+		if len(referencePattern) == 0 {
+			log.Panic("The reference pattern has to contain something")
+		}
+		if len(referencePattern) > math.MaxUint32 {
+			log.Panicf("The reference pattern length cannot exceed %d", math.MaxUint32)
+		}
+
+		swap := newSwap(referencePattern)
+		// the frames of memory, and the counter of the page in each of them, a page keeps it's frame until it is swapped out
+		frames := make([]*Page, 0, FRAME_SIZE)
+		counters := make([]uint64, 0, FRAME_SIZE)
+		frameOf := make(map[uint32]int, FRAME_SIZE)
+		referenced := uint64(1) << (bits - 1)
+
+		for i, page := range referencePattern {
+			for frame := range counters {
+				counters[frame] >>= 1
+			}
+			if frame, inMemory := frameOf[page]; inMemory {
+				counters[frame] |= referenced
+				continue
+			}
+
+			frame := len(frames)
+			if frame < FRAME_SIZE {
+				frames = append(frames, nil)
+				counters = append(counters, 0)
+			} else {
+				// if there is no space left in memory we need to move a page to swap, the first one with the lowest counter goes
+				frame = 0
+				for f := range counters {
+					if counters[f] < counters[frame] {
+						frame = f
+					}
+				}
+				victimPage := frames[frame]
+				delete(frameOf, victimPage.id)
+				swap[victimPage.id] = victimPage
+				victimPage.swappedOutAt = append(victimPage.swappedOutAt, uint32(i))
+			}
+
+			frames[frame] = swap[page]
+			delete(swap, page)
+			frameOf[page] = frame
+			counters[frame] = referenced
+			frames[frame].pageFaultAt = append(frames[frame].pageFaultAt, uint32(i))
+		}
+
+		memory := make(map[uint32]*Page, len(frames))
+		for _, page := range frames {
+			memory[page.id] = page
+		}
+		return collect(swap, memory)
+	}
+}
+
+// newSwap returns every page referenced in the reference pattern, all of them starting out in swap,
+// the page faults and swap outs are only allocated as they happen, since making room for the whole reference pattern
+// in every page would not fit in memory for long reference patterns
+func newSwap(referencePattern []uint32) map[uint32]*Page {
+	swap := make(map[uint32]*Page)
+	for _, page := range referencePattern {
+		if _, ok := swap[page]; !ok {
+			swap[page] = &Page{page, 0, nil, nil}
+		}
+	}
+	return swap
+}
+
+// collect returns the pages that ended up in swap and in memory sorted by their id,
+// so that the results do not depend on the order the maps are iterated in, and the same reference pattern always gives the same output
+func collect(swap, memory map[uint32]*Page) *Slice {
+	res := Slice(make([]Page, 0, len(swap)+len(memory)))
 	for page := range maps.Values(swap) {
 		res = append(res, *page)
 	}
 	for page := range maps.Values(memory) {
 		res = append(res, *page)
 	}
+	slices.SortFunc(res, func(a, b Page) int {
+		return cmp.Compare(a.id, b.id)
+	})
 	return &res
 }
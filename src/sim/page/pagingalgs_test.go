@@ -0,0 +1,100 @@
+package page
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// loop returns the pages from 0 to numPages-1, times times in a row
+func loop(numPages, times uint32) (referencePattern []uint32) {
+	for range times {
+		for page := range numPages {
+			referencePattern = append(referencePattern, page)
+		}
+	}
+	return referencePattern
+}
+
+// faults returns the number of page faults, and the pages that were swapped out at every reference
+func faults(pages *Slice) (num int, swappedOut map[uint32][]uint32) {
+	swappedOut = make(map[uint32][]uint32)
+	for _, page := range *pages {
+		num += len(page.pageFaultAt)
+		for _, at := range page.swappedOutAt {
+			swappedOut[at] = append(swappedOut[at], page.id)
+		}
+	}
+	return num, swappedOut
+}
+
+// loopSwappedOut is which page LRU swaps out at every reference of a loop over FRAME_SIZE+1 pages repeated twice,
+// once memory is full, it's always the page that was referenced FRAME_SIZE references ago
+func loopSwappedOut() map[uint32][]uint32 {
+	swappedOut := make(map[uint32][]uint32)
+	for i := uint32(FRAME_SIZE); i < 2*(FRAME_SIZE+1); i++ {
+		swappedOut[i] = []uint32{(i - FRAME_SIZE) % (FRAME_SIZE + 1)}
+	}
+	return swappedOut
+}
+
+// the reference patterns are the textbook ones, written for FRAME_SIZE frames instead of 3 or 4
+var lruTests = map[string]struct {
+	referencePattern []uint32
+	faults           int
+	swappedOut       map[uint32][]uint32
+}{
+	// once memory is full, a page that fits is never swapped out
+	"loop that fits": {loop(FRAME_SIZE, 3), FRAME_SIZE, map[uint32][]uint32{}},
+	// a loop over one page more than fits makes LRU swap out the page that is needed next, and fault on every reference
+	"loop that does not fit": {loop(FRAME_SIZE+1, 2), 2 * (FRAME_SIZE + 1), loopSwappedOut()},
+	// referencing the first page again keeps it in memory, where FIFO would swap it out, and fault on it right after
+	"rereferenced page": {append(loop(FRAME_SIZE, 1), 0, FRAME_SIZE, 0), FRAME_SIZE + 1, map[uint32][]uint32{FRAME_SIZE + 1: {1}}},
+	// every page referenced since the last fault is kept, and the one referenced the longest time ago goes
+	"most recent pages stay": {append(loop(FRAME_SIZE, 1), 3, 1, 0, 2, FRAME_SIZE, FRAME_SIZE+1, FRAME_SIZE+2, 4, 3),
+		FRAME_SIZE + 4, map[uint32][]uint32{FRAME_SIZE + 4: {4}, FRAME_SIZE + 5: {5}, FRAME_SIZE + 6: {6}, FRAME_SIZE + 7: {7}}},
+}
+
+func TestLRU(t *testing.T) {
+	for name, test := range lruTests {
+		num, swappedOut := faults(LRU(test.referencePattern))
+		if num != test.faults {
+			t.Errorf("%s: LRU had %d page faults, want %d", name, num, test.faults)
+		}
+		if !reflect.DeepEqual(swappedOut, test.swappedOut) {
+			t.Errorf("%s: LRU swapped out %v, want %v", name, swappedOut, test.swappedOut)
+		}
+	}
+}
+
+// with 64 bit counters, every page in memory that was referenced in the last 64 references has a different counter,
+// in the order they were last referenced in, so Aging swaps out the same pages as LRU as long as the pattern is that short
+func TestAgingMatchesLRU(t *testing.T) {
+	patterns := [][]uint32{
+		{7, 0, 1, 2, 0, 3, 0, 4, 2, 3, 0, 3, 2, 1, 2, 0, 1, 7, 0, 1},
+		append(loop(FRAME_SIZE+4, 1), 2, 19, 0, 7, 1, 18, 5, 16, 3, 2, 12, 17, 0, 4, 6),
+		slices.Concat(loop(FRAME_SIZE, 1), []uint32{20, 21, 3, 22, 1, 23, 0}, loop(FRAME_SIZE+2, 1)),
+	}
+	for _, test := range lruTests {
+		if len(test.referencePattern) <= 64 {
+			patterns = append(patterns, test.referencePattern)
+		}
+	}
+	for i, referencePattern := range patterns {
+		if len(referencePattern) > 64 {
+			t.Fatalf("pattern %d is longer than the counters", i)
+		}
+		if !reflect.DeepEqual(Aging(64)(referencePattern).Records(), LRU(referencePattern).Records()) {
+			t.Errorf("pattern %d: Aging with 64 bits did not swap out the same pages as LRU", i)
+		}
+	}
+}
+
+// a single bit only tells which page was referenced last, so after 1 and 0 are referenced again, LRU swaps out 2,
+// and Aging swaps out 1, the first page without the bit set
+func TestAgingWithOneBitIsNotLRU(t *testing.T) {
+	referencePattern := append(loop(FRAME_SIZE, 1), 1, 0, FRAME_SIZE)
+	if reflect.DeepEqual(Aging(1)(referencePattern).Records(), LRU(referencePattern).Records()) {
+		t.Error("Aging with 1 bit swapped out the same pages as LRU")
+	}
+}
@@ -0,0 +1,226 @@
+package process
+
+// cpu executes the processes it's policy chooses, it is driven by the simulation loop, which first lets it schedule,
+// and then execute for as long as nothing happens that could change what the cpu should be doing
+type cpu struct {
+	policy policy
+	// the process that currently has the cpu, nil if the cpu is idle
+	running *Process
+	// the process that had the cpu last, so that we know whether giving it to another one is a context switch
+	last *Process
+	// for how long the running process has been executing since it got the cpu
+	slice uint32
+	// whether the running process has used up it's quantum, and has to give up the cpu once the new arrivals are in
+	expired bool
+	// for how long the cpu has to work on something other than the running process before it can continue executing it
+	overhead uint32
+	// the overhead processes still have to pay on the next cpu that gets them, like for migrating them between cores,
+	// it can be shared between cpus, and is nil when there is nothing like that to pay
+	pendingOverhead map[*Process]uint32
+	// the i/o device processes block on when they finish a cpu burst
+	device *device
+	costs  switchCosts
+	stats  Core
+	// what the cpu was doing during the simulation
+	timeline Timeline
+	// tickets is the sum of the tickets of the processes that are waiting for the cpu or running on it,
+	// and fairTime is how much cpu time a process with a single ticket should have gotten from the cpu since the start,
+	// it's only brought up to date when tickets changes, at sharedAt, so that both engines add up the same numbers
+	tickets  uint64
+	fairTime float64
+	sharedAt uint32
+	joinedAt map[*Process]float64
+}
+
+// switchCosts are the units of time a cpu spends on switching between processes before it can execute the new one
+type switchCosts struct {
+	// contextSwitch is paid every time the cpu is given to a different process
+	contextSwitch uint32
+	// addressSpace is paid on top of it, when the new process does not share the address space of the last one
+	addressSpace uint32
+}
+
+func newCPU(id uint32, p policy, d *device, costs switchCosts) *cpu {
+	if cp, ok := p.(corePolicy); ok {
+		cp.onCore(id)
+	}
+	return &cpu{policy: p, device: d, costs: costs, stats: Core{id: id}, joinedAt: make(map[*Process]float64)}
+}
+
+// busy reports whether the cpu has a process to execute, or ones waiting for it
+func (c *cpu) busy() bool {
+	return c.running != nil || c.policy.len() != 0
+}
+
+// load is the number of processes the cpu has, waiting or running
+func (c *cpu) load() int {
+	if c.running != nil {
+		return c.policy.len() + 1
+	}
+	return c.policy.len()
+}
+
+// add gives the cpu a process that became ready at time, because it arrived, came back from i/o, or was migrated to it,
+// from then on it competes for the cpu with the other processes on it
+func (c *cpu) add(proc *Process, time uint32) {
+	c.share(time)
+	c.tickets += uint64(proc.tickets)
+	c.joinedAt[proc] = c.fairTime
+	c.policy.push(proc)
+}
+
+// take takes the next waiting process away from the cpu at time, to migrate it to another one
+func (c *cpu) take(time uint32) *Process {
+	proc := c.policy.remove()
+	c.leave(proc, time)
+	return proc
+}
+
+// leave stops proc from competing for the cpu at time, and adds the cpu time it should have gotten from it to it's expectedShare
+func (c *cpu) leave(proc *Process, time uint32) {
+	c.share(time)
+	c.tickets -= uint64(proc.tickets)
+	proc.expectedShare += float64(proc.tickets) * (c.fairTime - c.joinedAt[proc])
+	delete(c.joinedAt, proc)
+}
+
+// share brings fairTime up to time, the processes on the cpu should have split the time since sharedAt by their tickets
+func (c *cpu) share(time uint32) {
+	if c.tickets != 0 {
+		c.fairTime += float64(time-c.sharedAt) / float64(c.tickets)
+	}
+	c.sharedAt = time
+}
+
+// schedule decides which process the cpu is going to execute during the next unit of time
+func (c *cpu) schedule() {
+	// a process that used up it's quantum goes back to the policy behind everything that arrived while it was running
+	if c.expired {
+		c.policy.push(c.running)
+		c.running, c.expired = nil, false
+	}
+	if c.running == nil && c.policy.len() != 0 {
+		c.dispatch(c.policy.pop())
+	}
+	// we take the next process before giving back the running one, otherwise a stack would just give it right back,
+	// and a cpu that is in the middle of switching to a process has to finish it before it can be preempted,
+	// the process that was just given the cpu can be preempted too, for example by one that came back from i/o,
+	// and this repeats until there is nothing left to preempt it, so that scheduling again without anything happening changes nothing
+	for c.running != nil && c.overhead == 0 && c.policy.len() != 0 && c.policy.preempts(c.running) {
+		next := c.policy.pop()
+		c.policy.push(c.running)
+		c.dispatch(next)
+	}
+}
+
+func (c *cpu) dispatch(proc *Process) {
+	// giving the cpu to a different process than the one that had it last is a context switch,
+	// and it costs more when the new process is in another address space
+	if c.last != nil && c.last != proc {
+		c.stats.switches++
+		c.overhead += c.costs.contextSwitch
+		if c.last.addressSpace != proc.addressSpace {
+			c.overhead += c.costs.addressSpace
+		}
+	}
+	c.running, c.last, c.slice = proc, proc, 0
+	if overhead, ok := c.pendingOverhead[proc]; ok {
+		c.overhead += overhead
+		delete(c.pendingOverhead, proc)
+	}
+}
+
+// nextEvent returns in how many units of time the cpu is going to need to schedule again, because the process it is executing
+// could finish, block, use up it's quantum or be preempted, or because it finished working on it's overhead,
+// an idle cpu has nothing to wait for, so it returns NEVER
+func (c *cpu) nextEvent() uint64 {
+	if c.running == nil {
+		return NEVER
+	}
+	if c.overhead != 0 {
+		return uint64(c.overhead)
+	}
+
+	next := uint64(c.running.executionTimeLeft)
+	if len(c.running.bursts) != 0 {
+		next = uint64(c.running.bursts[c.running.burst] - c.running.burstTime)
+	}
+	// a process whose quantum ran out gave up the cpu in schedule, so there is always some of it left
+	if quantum := c.policy.quantum(c.running); quantum != 0 {
+		next = min(next, uint64(quantum-c.slice))
+	}
+	if decision := c.policy.nextDecision(c.running); decision != 0 {
+		next = min(next, uint64(decision))
+	}
+	return next
+}
+
+// execute runs the cpu for the elapsed units of time that end at time, which cannot go past it's nextEvent,
+// and returns the process that finished at time, if any
+func (c *cpu) execute(time, elapsed uint32) (finished *Process) {
+	// if there are no processes waiting we just wait for them to arrive
+	if c.running == nil {
+		c.stats.idleTime += elapsed
+		c.record(time-elapsed, time, IDLE, nil)
+		c.policy.tick(nil, elapsed)
+		return nil
+	}
+
+	c.stats.busyTime += elapsed
+	if c.overhead != 0 {
+		c.overhead -= elapsed
+		c.stats.overheadTime += elapsed
+		c.record(time-elapsed, time, OVERHEAD, c.running)
+		c.policy.tick(nil, elapsed)
+		return nil
+	}
+	c.record(time-elapsed, time, RUNNING, c.running)
+
+	if c.running.executionTimeLeft == c.running.executionTime {
+		// the time that is ending now started at time - elapsed
+		c.running.responseTime = time - elapsed - c.running.arriveTime
+	}
+	c.running.executionTimeLeft -= elapsed
+	c.slice += elapsed
+	burstOver := c.running.advanceBurst(elapsed)
+	c.policy.tick(c.running, elapsed)
+	if c.running.executionTimeLeft == 0 {
+		finished = c.running
+		finished.completionTime = time
+		finished.turnaroundTime = time - finished.arriveTime
+		finished.waitTime = finished.turnaroundTime - finished.executionTime - finished.blockedTime
+		finished.cpuShare = float64(finished.executionTime) / float64(finished.turnaroundTime)
+		c.leave(finished, time)
+		finished.expectedShare /= float64(finished.turnaroundTime)
+		c.running = nil
+		c.stats.finished++
+		return finished
+	}
+	// a process that finished it's cpu burst gives up the cpu, and waits for the i/o device
+	if burstOver {
+		c.leave(c.running, time)
+		c.device.block(c.running, time, c.stats.id)
+		c.running = nil
+		return nil
+	}
+	// the quantum can shrink while the process is running, for example when MLFQ boosts it to a higher queue
+	if quantum := c.policy.quantum(c.running); quantum != 0 && c.slice >= quantum {
+		c.expired = true
+	}
+	return nil
+}
+
+// record adds the time from start to end to the timeline, the last segment is extended if the cpu kept doing the same thing
+func (c *cpu) record(start, end uint32, state string, proc *Process) {
+	id := int64(-1)
+	if proc != nil {
+		id = int64(proc.id)
+	}
+	if n := len(c.timeline); n != 0 {
+		if last := &c.timeline[n-1]; last.end == start && last.state == state && last.process == id {
+			last.end = end
+			return
+		}
+	}
+	c.timeline = append(c.timeline, Segment{core: c.stats.id, start: start, end: end, state: state, process: id})
+}
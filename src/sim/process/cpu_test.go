@@ -0,0 +1,25 @@
+package process
+
+import (
+	"math"
+	"testing"
+)
+
+// the processes on a cpu split the time it had any of them ready by their tickets, so without i/o and context switches,
+// the cpu time all of them should have gotten adds up to the time the cpu was busy
+func TestExpectedSharesAddUpToTheBusyTime(t *testing.T) {
+	processes := testWorkload(6, 64, 256, 16, UniformBursts(), 0)
+	for name, newPolicy := range map[string]Policy{"Lottery": LotteryPolicy(4, 1), "Stride": StridePolicy(4), "FCFS": FCFSPolicy} {
+		res, cores, _ := SimUniprocessor(processes, Events, 0, 0, newPolicy)
+		var expected float64
+		for _, proc := range *res[0] {
+			if proc.expectedShare <= 0 || proc.expectedShare > 1+1e-9 {
+				t.Errorf("%s: process %d has an expected share of %g", name, proc.id, proc.expectedShare)
+			}
+			expected += proc.expectedShare * float64(proc.turnaroundTime)
+		}
+		if busy := float64((*cores[0])[0].busyTime); math.Abs(expected-busy) > 1e-6*busy {
+			t.Errorf("%s: the processes should have gotten %g units of cpu time, but the cpu was busy for %g", name, expected, busy)
+		}
+	}
+}
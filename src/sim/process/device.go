@@ -0,0 +1,74 @@
+package process
+
+import "src/sim"
+
+// device is the i/o device of the simulation, shared by all the cpus, processes that finished a cpu burst
+// wait in it's queue, and it serves them one at a time in the order they came, for the length of their i/o burst
+type device struct {
+	queue *sim.Queue[*Process]
+	// the process whose i/o burst is being done, nil if the device is idle
+	running *Process
+	// when every blocked process started waiting for the device, so that we know for how long it was blocked
+	blockedAt map[*Process]uint32
+	// the core every blocked process was taken off, so that it can go back to the same one
+	blockedOn map[*Process]uint32
+	// the processes that finished their i/o burst, and have not been given back to a cpu yet
+	done []*Process
+}
+
+func newDevice(numProcesses int) *device {
+	return &device{queue: sim.NewQueue[*Process](numProcesses),
+		blockedAt: make(map[*Process]uint32), blockedOn: make(map[*Process]uint32)}
+}
+
+// busy reports whether the device has processes blocked on it, or ones that are ready to be given back to a cpu
+func (d *device) busy() bool {
+	return d.running != nil || !d.queue.Empty() || len(d.done) != 0
+}
+
+// block makes proc wait for the device, it was taken off the given core at time
+func (d *device) block(proc *Process, time uint32, core uint32) {
+	d.blockedAt[proc] = time
+	d.blockedOn[proc] = core
+	d.queue.Push(proc)
+}
+
+// unblock returns the processes that finished their i/o burst, so that they can wait for a cpu again,
+// and the cores they blocked on
+func (d *device) unblock() (ready []*Process, cores []uint32) {
+	ready, d.done = d.done, nil
+	cores = make([]uint32, len(ready))
+	for i, proc := range ready {
+		cores[i] = d.blockedOn[proc]
+		delete(d.blockedOn, proc)
+	}
+	return ready, cores
+}
+
+// schedule starts the i/o burst of the next waiting process, if the device is idle
+func (d *device) schedule() {
+	if d.running == nil && !d.queue.Empty() {
+		d.running = d.queue.Pop()
+	}
+}
+
+// nextEvent returns in how many units of time the i/o burst that is being done is going to end, NEVER if the device is idle
+func (d *device) nextEvent() uint64 {
+	if d.running == nil {
+		return NEVER
+	}
+	return uint64(d.running.bursts[d.running.burst] - d.running.burstTime)
+}
+
+// execute runs the device for the elapsed units of time that end at time, which cannot go past it's nextEvent
+func (d *device) execute(time, elapsed uint32) {
+	if d.running == nil {
+		return
+	}
+	if d.running.advanceBurst(elapsed) {
+		d.running.blockedTime += time - d.blockedAt[d.running]
+		delete(d.blockedAt, d.running)
+		d.done = append(d.done, d.running)
+		d.running = nil
+	}
+}
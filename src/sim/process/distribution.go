@@ -0,0 +1,173 @@
+package process
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// Arrivals decides how the arrive times of generated processes are drawn
+type Arrivals uint8
+
+const (
+	// UniformArrivals draws every arrive time separately, uniformly between 0 and the maximum arrive time
+	UniformArrivals Arrivals = iota
+	// PoissonArrivals makes the processes arrive in a poisson process, the gaps between them are drawn from an exponential distribution,
+	// with a mean that makes the last one arrive around the maximum arrive time
+	PoissonArrivals
+)
+
+var arrivalsNames = [...]string{"uniform", "poisson"}
+
+func (a Arrivals) String() string {
+	if int(a) >= len(arrivalsNames) {
+		return fmt.Sprint("Arrivals(", uint8(a), ")")
+	}
+	return arrivalsNames[a]
+}
+
+// ParseArrivals returns the Arrivals with the given name, as returned by String
+func ParseArrivals(name string) (a Arrivals, ok bool) {
+	i := slices.Index(arrivalsNames[:], name)
+	if i == -1 {
+		return UniformArrivals, false
+	}
+	return Arrivals(i), true
+}
+
+// genArriveTimes returns num arrive times drawn like arrivals says, that do not go past maxArriveTime in the uniform case
+func genArriveTimes(rng *rand.Rand, arrivals Arrivals, num, maxArriveTime uint32) (arriveTimes []uint32) {
+	arriveTimes = make([]uint32, num)
+	switch arrivals {
+	case UniformArrivals:
+		for i := range arriveTimes {
+			arriveTimes[i] = uint32(rng.UintN(uint(maxArriveTime) + 1))
+		}
+	case PoissonArrivals:
+		mean := float64(maxArriveTime) / float64(num)
+		var time float64
+		for i := range arriveTimes {
+			arriveTimes[i] = uint32(math.Round(time))
+			time += rng.ExpFloat64() * mean
+			if math.Round(time) > math.MaxUint32 {
+				log.Panicf("The poisson arrive times cannot fit in 32 bits, with a mean gap of %g", mean)
+			}
+		}
+	default:
+		log.Panicf("Cannot generate %s arrive times", arrivals)
+	}
+	return arriveTimes
+}
+
+// Distribution is a probability distribution of the lengths of the cpu bursts of generated processes
+type Distribution interface {
+	fmt.Stringer
+	// draw returns a random burst length drawn with rng, between 1 and maxExecutionTime
+	draw(rng *rand.Rand, maxExecutionTime uint32) uint32
+}
+
+// clamp rounds a burst length drawn from a continuous distribution, the distributions are cut off at 1 and maxExecutionTime,
+// so that every burst takes some time, and the heavy tailed ones cannot overflow
+func clamp(length float64, maxExecutionTime uint32) uint32 {
+	return uint32(min(float64(maxExecutionTime), max(1, math.Round(length))))
+}
+
+type uniformDistribution struct{}
+
+// UniformBursts draws every burst length between 1 and the maximum execution time with the same probability
+func UniformBursts() Distribution { return uniformDistribution{} }
+
+func (uniformDistribution) String() string { return "uniform" }
+func (uniformDistribution) draw(rng *rand.Rand, maxExecutionTime uint32) uint32 {
+	return uint32(1 + rng.UintN(uint(maxExecutionTime)))
+}
+
+type exponentialDistribution struct{ mean float64 }
+
+// ExponentialBursts draws the burst lengths from an exponential distribution with the given mean, most bursts are short,
+// and the longer ones get exponentially less likely
+func ExponentialBursts(mean float64) Distribution {
+	if mean <= 0 {
+		log.Panic("The mean of the exponential distribution has to be greater than zero")
+	}
+	return exponentialDistribution{mean}
+}
+
+func (e exponentialDistribution) String() string { return fmt.Sprint("exponential-", e.mean, "-mean") }
+func (e exponentialDistribution) draw(rng *rand.Rand, maxExecutionTime uint32) uint32 {
+	return clamp(rng.ExpFloat64()*e.mean, maxExecutionTime)
+}
+
+type logNormalDistribution struct{ mean, sigma float64 }
+
+// LogNormalBursts draws the burst lengths from a log-normal distribution with the given mean,
+// sigma is the standard deviation of the logarithm of the lengths, the bigger it is, the longer the tail
+func LogNormalBursts(mean, sigma float64) Distribution {
+	if mean <= 0 {
+		log.Panic("The mean of the log-normal distribution has to be greater than zero")
+	}
+	if sigma < 0 {
+		log.Panic("The sigma of the log-normal distribution cannot be negative")
+	}
+	return logNormalDistribution{mean, sigma}
+}
+
+func (l logNormalDistribution) String() string {
+	return fmt.Sprint("lognormal-", l.mean, "-mean-", l.sigma, "-sigma")
+}
+func (l logNormalDistribution) draw(rng *rand.Rand, maxExecutionTime uint32) uint32 {
+	// the mean of a log-normal distribution is exp(mu + sigma^2 / 2), so this is the mu that gives the mean we want
+	mu := math.Log(l.mean) - l.sigma*l.sigma/2
+	return clamp(math.Exp(mu+rng.NormFloat64()*l.sigma), maxExecutionTime)
+}
+
+type paretoDistribution struct{ mean, shape float64 }
+
+// ParetoBursts draws the burst lengths from a heavy tailed Pareto distribution with the given mean,
+// the closer the shape is to 1, the more of the total execution time is in a few very long bursts
+func ParetoBursts(mean, shape float64) Distribution {
+	if mean <= 0 {
+		log.Panic("The mean of the Pareto distribution has to be greater than zero")
+	}
+	if shape <= 1 {
+		log.Panic("The shape of the Pareto distribution has to be greater than 1, otherwise it does not have a mean")
+	}
+	return paretoDistribution{mean, shape}
+}
+
+func (p paretoDistribution) String() string {
+	return fmt.Sprint("pareto-", p.mean, "-mean-", p.shape, "-shape")
+}
+func (p paretoDistribution) draw(rng *rand.Rand, maxExecutionTime uint32) uint32 {
+	// the mean of a Pareto distribution is shape * scale / (shape - 1), where the scale is the shortest possible length
+	scale := p.mean * (p.shape - 1) / p.shape
+	// 1 - Float64 is never 0, so the length is always finite
+	return clamp(scale/math.Pow(1-rng.Float64(), 1/p.shape), maxExecutionTime)
+}
+
+type bimodalDistribution struct{ shortMean, longMean, longFraction float64 }
+
+// BimodalBursts draws the burst lengths around two peaks, like a mix of interactive and batch processes,
+// longFraction of the bursts are around longMean, and the rest around shortMean, both with a standard deviation of a quarter of their mean
+func BimodalBursts(shortMean, longMean, longFraction float64) Distribution {
+	if shortMean <= 0 || longMean <= 0 {
+		log.Panic("The means of the bimodal distribution have to be greater than zero")
+	}
+	if longFraction < 0 || longFraction > 1 {
+		log.Panic("The fraction of long bursts of the bimodal distribution has to be between 0 and 1")
+	}
+	return bimodalDistribution{shortMean, longMean, longFraction}
+}
+
+func (b bimodalDistribution) String() string {
+	return fmt.Sprint("bimodal-", b.shortMean, "-", b.longMean, "-mean-", b.longFraction, "-long")
+}
+func (b bimodalDistribution) draw(rng *rand.Rand, maxExecutionTime uint32) uint32 {
+	mean := b.shortMean
+	if rng.Float64() < b.longFraction {
+		mean = b.longMean
+	}
+	return clamp(mean+rng.NormFloat64()*mean/4, maxExecutionTime)
+}
@@ -0,0 +1,101 @@
+package process
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+// testPolicies are all the scheduling policies, with the parameters main uses by default, and aging turned on
+func testPolicies() map[string]Policy {
+	return map[string]Policy{
+		"FCFS":                    FCFSPolicy,
+		"PreemptiveFCFS":          PreemptiveFCFSPolicy,
+		"LCFS":                    LCFSPolicy,
+		"PreemptiveLCFS":          PreemptiveLCFSPolicy,
+		"SJF":                     SJFPolicy,
+		"PreemptiveSJF":           PreemptiveSJFPolicy,
+		"PredictiveSJF":           PredictiveSJFPolicy(0.5, 8),
+		"PreemptivePredictiveSJF": PreemptivePredictiveSJFPolicy(0.5, 8),
+		"HRRN":                    HRRNPolicy,
+		"Priority":                PriorityPolicy(3),
+		"PreemptivePriority":      PreemptivePriorityPolicy(3),
+		"MLFQ":                    MLFQPolicy([]uint32{2, 4, 8}, 64),
+		"CFS":                     CFSPolicy(24, 3),
+		"RoundRobin":              RoundRobinPolicy(4),
+		"Lottery":                 LotteryPolicy(4, 1),
+		"Stride":                  StridePolicy(4),
+	}
+}
+
+// testWorkload generates the same processes every time for the given seed, with i/o bursts if maxIOBursts is not 0
+func testWorkload(seed uint64, num, maxArriveTime, maxExecutionTime uint32, bursts Distribution, maxIOBursts uint32) *Slice {
+	return Gen(rand.NewPCG(seed, 0), GenConfig{Num: num, MaxArriveTime: maxArriveTime, Arrivals: PoissonArrivals,
+		MaxExecutionTime: maxExecutionTime, Bursts: bursts, PriorityLevels: 8, MaxTickets: 100, MaxNice: 10,
+		MaxIOBursts: maxIOBursts, MaxIOTime: 8, AddressSpaces: 4})
+}
+
+func sameResults(t *testing.T, name string, res, otherRes []*Slice, cores, otherCores []*CoreSlice, timelines, otherTimelines []*Timeline) {
+	t.Helper()
+	for i := range res {
+		if !reflect.DeepEqual(res[i].Records(), otherRes[i].Records()) {
+			t.Errorf("%s: the engines gave different process results", name)
+		}
+		if !reflect.DeepEqual(cores[i].Records(), otherCores[i].Records()) {
+			t.Errorf("%s: the engines gave different core results", name)
+		}
+		if !reflect.DeepEqual(timelines[i].Records(), otherTimelines[i].Records()) {
+			t.Errorf("%s: the engines gave different timelines", name)
+		}
+	}
+}
+
+// the Events engine skips over the time in which nothing can happen, so it has to end up exactly where the Ticks engine does
+func TestEnginesGiveTheSameResults(t *testing.T) {
+	workloads := []*Slice{
+		testWorkload(1, 64, 256, 16, UniformBursts(), 0),
+		testWorkload(2, 64, 256, 16, UniformBursts(), 3),
+		testWorkload(3, 96, 2048, 200, ParetoBursts(20, 1.5), 2),
+		testWorkload(4, 96, 512, 100, BimodalBursts(3, 60, 0.2), 1),
+	}
+	for name, newPolicy := range testPolicies() {
+		for i, processes := range workloads {
+			for _, costs := range [][2]uint32{{0, 0}, {1, 2}} {
+				res, cores, timelines := SimUniprocessor(processes, Events, costs[0], costs[1], newPolicy)
+				otherRes, otherCores, otherTimelines := SimUniprocessor(processes, Ticks, costs[0], costs[1], newPolicy)
+				sameResults(t, fmt.Sprint(name, " uniprocessor workload ", i, " costs ", costs), res, otherRes, cores, otherCores, timelines, otherTimelines)
+
+				for _, balancer := range []Balancer{NoBalancing, PushMigration, PullMigration, WorkStealing} {
+					res, cores, timelines := SimSMP(processes, Events, 3, balancer, 5, 2, costs[0], costs[1], newPolicy)
+					otherRes, otherCores, otherTimelines := SimSMP(processes, Ticks, 3, balancer, 5, 2, costs[0], costs[1], newPolicy)
+					sameResults(t, fmt.Sprint(name, " smp ", balancer, " workload ", i, " costs ", costs), res, otherRes, cores, otherCores, timelines, otherTimelines)
+				}
+			}
+		}
+	}
+}
+
+// the Events engine is only faster when there is time to skip, these are a workload with long bursts and idle gaps, where it is,
+// and one where something happens almost every unit of time, where it is not
+func BenchmarkEngines(b *testing.B) {
+	workloads := []struct {
+		name      string
+		processes *Slice
+	}{
+		{"long-bursts", testWorkload(1, 1000, 1000000, 1000, UniformBursts(), 0)},
+		{"short-bursts", testWorkload(1, 1000, 4000, 16, UniformBursts(), 2)},
+	}
+	policies := testPolicies()
+	for _, w := range workloads {
+		for _, name := range []string{"FCFS", "PreemptiveSJF", "PreemptivePriority", "RoundRobin", "CFS"} {
+			for _, engine := range []Engine{Events, Ticks} {
+				b.Run(fmt.Sprint(w.name, "/", name, "/", engine), func(b *testing.B) {
+					for range b.N {
+						SimUniprocessor(w.processes, engine, 0, 0, policies[name])
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package process
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Load reads the processes to simulate from the csv file at path, it can either be a process.csv file saved by the simulation,
+// from which only the columns that describe the processes are read, and the results are left out,
+// or a simpler file with the id, arrive time and execution time of every process in that order, with or without a header,
+// the processes are checked and sorted by arriveTime, so that they can be simulated right away
+func Load(path string) *Slice {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Panic(err)
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		log.Panicf("%s: the process file cannot be empty", path)
+	}
+	if err != nil {
+		log.Panic(err)
+	}
+
+	// the columns of the simple format are always in the same order, and the header can be left out
+	columns := map[string]int{"id": 0, "arriveTime": 1, "executionTime": 2}
+	var records [][]string
+	// the line of the first process, for the error messages
+	firstLine := 2
+	if slices.Contains(header, "arriveTime") {
+		columns = make(map[string]int, len(header))
+		for i, name := range header {
+			columns[name] = i
+		}
+		for _, name := range []string{"id", "arriveTime", "executionTime"} {
+			if _, ok := columns[name]; !ok {
+				log.Panicf("%s: the process file has no %s column", path, name)
+			}
+		}
+	} else if len(header) != 3 {
+		log.Panicf("%s: a process file without the columns of process.csv has to have 3: id, arrive time and execution time, got %d", path, len(header))
+	} else if !isSimpleHeader(header) {
+		// a row with a number in it is a process, so that a malformed one is reported instead of being skipped as a header
+		records = append(records, header)
+		firstLine = 1
+	}
+
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			log.Panic(err)
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		log.Panicf("%s: the process file has no processes in it", path)
+	}
+
+	var processes Slice = make([]Process, len(records))
+	ids := make(map[uint32]bool, len(records))
+	for i, record := range records {
+		line := firstLine + i
+		proc := &processes[i]
+		proc.id = parseUint(path, line, "id", record[columns["id"]])
+		proc.arriveTime = parseUint(path, line, "arriveTime", record[columns["arriveTime"]])
+		proc.executionTime = parseUint(path, line, "executionTime", record[columns["executionTime"]])
+		proc.tickets = 1
+		proc.addressSpace = proc.id
+		if col, ok := columns["priority"]; ok {
+			proc.priority = parseUint(path, line, "priority", record[col])
+		}
+		if col, ok := columns["tickets"]; ok {
+			proc.tickets = parseUint(path, line, "tickets", record[col])
+		}
+		if col, ok := columns["addressSpace"]; ok {
+			proc.addressSpace = parseUint(path, line, "addressSpace", record[col])
+		}
+		if col, ok := columns["nice"]; ok {
+			nice, err := strconv.ParseInt(record[col], 10, 8)
+			if err != nil || nice < -MAX_NICE-1 || nice > MAX_NICE {
+				log.Panicf("%s:%d: nice has to be between %d and %d, got: %q", path, line, -MAX_NICE-1, MAX_NICE, record[col])
+			}
+			proc.nice = int8(nice)
+		}
+		if col, ok := columns["bursts"]; ok {
+			// the bursts are saved the way fmt prints a slice, like [3 5 2]
+			for _, field := range strings.Fields(strings.Trim(record[col], "[]")) {
+				proc.bursts = append(proc.bursts, parseUint(path, line, "bursts", field))
+			}
+		}
+		proc.executionTimeLeft = proc.executionTime
+
+		switch {
+		case ids[proc.id]:
+			log.Panicf("%s:%d: there is more than one process with id %d", path, line, proc.id)
+		case proc.executionTime == 0:
+			log.Panicf("%s:%d: the execution time of a process has to be greater than zero", path, line)
+		case proc.tickets == 0:
+			log.Panicf("%s:%d: a process has to have at least one ticket", path, line)
+		case len(proc.bursts) != 0 && (len(proc.bursts)%2 == 0 || slices.Contains(proc.bursts, 0)):
+			log.Panicf("%s:%d: the bursts of a process have to be non zero, and start and end with a cpu burst", path, line)
+		}
+		ids[proc.id] = true
+		var cpuTime uint64
+		for j := 0; j < len(proc.bursts); j += 2 {
+			cpuTime += uint64(proc.bursts[j])
+		}
+		if len(proc.bursts) != 0 && cpuTime != uint64(proc.executionTime) {
+			log.Panicf("%s:%d: the cpu bursts of a process have to add up to it's execution time", path, line)
+		}
+	}
+
+	// the processes keep the order they were in the file when they arrive at the same time
+	slices.SortStableFunc(processes, func(a, b Process) int {
+		return cmp.Compare(a.arriveTime, b.arriveTime)
+	})
+	return &processes
+}
+
+// parseUint parses the value of the column called name on the given line of the process file at path
+func parseUint(path string, line int, name, s string) uint32 {
+	val, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		log.Panicf("%s:%d: %s has to be a 32 bit unsigned integer, got: %q", path, line, name, s)
+	}
+	return uint32(val)
+}
+
+// isSimpleHeader reports whether the first row of a file in the simple format names it's columns instead of being a process,
+// which it does when none of it's fields is a number, so any names work, like "id,arrive,burst" or "PID, Arrival, Burst"
+func isSimpleHeader(row []string) bool {
+	for _, field := range row {
+		if _, err := strconv.ParseFloat(field, 64); err == nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package process
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to a file called name in a temporary directory, and returns it's path
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// loadPanics reports whether loading the file at path panics, with a message that contains want
+func loadPanics(t *testing.T, path, want string) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		r := recover()
+		if r == nil {
+			t.Errorf("loading %s did not panic", path)
+		} else if msg, _ := r.(string); !strings.Contains(msg, want) {
+			t.Errorf("loading %s panicked with %q, want it to mention %q", path, r, want)
+		}
+	}()
+	Load(path)
+}
+
+func TestLoadSimple(t *testing.T) {
+	want := []struct{ id, arriveTime, executionTime uint32 }{{2, 0, 5}, {1, 0, 3}, {3, 4, 1}}
+	tests := map[string]string{
+		"without a header":         "3,4,1\n2,0,5\n1,0,3\n",
+		"with a header":            "id,arriveTime,executionTime\n3,4,1\n2,0,5\n1,0,3\n",
+		"with a different header":  "PID, Arrival, Burst\n3,4,1\n2,0,5\n1,0,3\n",
+		"with a short header":      "id,arrive,burst\n3, 4, 1\n2, 0, 5\n1, 0, 3\n",
+		"with a header and no eol": "pid,arrival,burst\n3,4,1\n2,0,5\n1,0,3",
+	}
+	for name, content := range tests {
+		processes := *Load(writeFile(t, "process.csv", content))
+		if len(processes) != len(want) {
+			t.Fatalf("%s: loaded %d processes, want %d", name, len(processes), len(want))
+		}
+		// the processes are sorted by arriveTime, and keep the order of the file when they arrive at the same time
+		for i, proc := range processes {
+			if proc.id != want[i].id || proc.arriveTime != want[i].arriveTime || proc.executionTime != want[i].executionTime {
+				t.Errorf("%s: process %d is %d,%d,%d, want %v", name, i, proc.id, proc.arriveTime, proc.executionTime, want[i])
+			}
+			if proc.executionTimeLeft != proc.executionTime || proc.tickets != 1 || proc.addressSpace != proc.id {
+				t.Errorf("%s: process %d was not given the defaults of the simple format", name, i)
+			}
+		}
+	}
+}
+
+func TestLoadRejectsInvalidProcesses(t *testing.T) {
+	tests := map[string]struct{ content, want string }{
+		"duplicate id":               {"id,arriveTime,executionTime\n1,0,3\n2,1,4\n1,2,5\n", "process.csv:4: there is more than one process with id 1"},
+		"zero execution time":        {"1,0,3\n2,1,0\n", "process.csv:2: the execution time"},
+		"malformed first process":    {"1,zero,3\n", "process.csv:1: arriveTime"},
+		"negative arrive time":       {"id,arrive,burst\n1,-1,3\n", "process.csv:2: arriveTime"},
+		"wrong number of columns":    {"1,0\n", "has to have 3"},
+		"empty file":                 {"", "cannot be empty"},
+		"header and no processes":    {"id,arrive,burst\n", "no processes"},
+		"missing process.csv column": {"id,arriveTime,tickets\n1,0,3\n", "no executionTime column"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			loadPanics(t, writeFile(t, "process.csv", test.content), test.want)
+		})
+	}
+}
+
+// a process.csv saved before the simulation has to load back into the exact same processes
+func TestLoadProcessCSVRoundTrip(t *testing.T) {
+	processes := testWorkload(5, 64, 256, 16, UniformBursts(), 3)
+
+	var content strings.Builder
+	w := csv.NewWriter(&content)
+	if err := w.WriteAll(processes.Records()); err != nil {
+		t.Fatal(err)
+	}
+	loaded := Load(writeFile(t, "process.csv", content.String()))
+
+	if !reflect.DeepEqual(loaded.Records(), processes.Records()) {
+		t.Error("the loaded processes are not the ones that were saved")
+	}
+}
@@ -0,0 +1,834 @@
+package process
+
+import (
+	"container/heap"
+	"log"
+	"math"
+	"math/bits"
+	"math/rand/v2"
+	"slices"
+	"src/sim"
+)
+
+// policy decides in what order the waiting processes get the cpu, it is what makes the scheduling algorithms different
+type policy interface {
+	// push gives the policy a process that is ready to be executed
+	push(proc *Process)
+	// pop removes and returns the process that should be executed next, it is only called right before it gets the cpu
+	pop() *Process
+	// remove removes and returns a waiting process so that it can be moved to another core,
+	// unlike pop it does not give the process the cpu, so nothing is prepared for it
+	remove() *Process
+	// peek returns the process that pop would return, without removing it
+	peek() *Process
+	// len returns the number of waiting processes
+	len() int
+	// preempts reports whether the process pop would return should take the cpu away from the running process,
+	// it is only called when there are waiting processes
+	preempts(running *Process) bool
+	// quantum returns for how long proc can be executed before it has to give up the cpu, 0 means until it is done
+	quantum(proc *Process) uint32
+	// tick is called after the cpu executed running for elapsed units of time, running is nil if the cpu was idle,
+	// the execution time left and the burst of the process are already updated
+	tick(running *Process, elapsed uint32)
+	// nextDecision returns in how many units of time the policy could change it's mind about preempting running,
+	// or about the quantum it gave it, even if no process arrives, finishes or blocks, 0 if that never happens,
+	// the simulation never lets more time than that pass before it lets the cpu schedule again
+	nextDecision(running *Process) uint32
+}
+
+// corePolicy can be implemented by a policy that has to know which core it is on, so that every core does not do exactly the same thing
+type corePolicy interface {
+	policy
+	// onCore is called once, before the policy is given any processes
+	onCore(core uint32)
+}
+
+// Policy creates a new instance of a scheduling policy for the given number of processes,
+// it lets every cpu have it's own instance of the same algorithm
+type Policy func(numProcesses int) policy
+
+// stackPolicy gives the cpu to the process that arrived last
+type stackPolicy struct {
+	// processes will be pushed onto this stack as they arrive, and so they will naturally be sorted
+	// from last to first, which is perfect for LCFS
+	stack      *sim.Stack[*Process]
+	preemptive bool
+}
+
+func newStackPolicy(len int, preemptive bool) *stackPolicy {
+	return &stackPolicy{sim.NewStack[*Process](len), preemptive}
+}
+
+func LCFSPolicy(numProcesses int) policy           { return newStackPolicy(numProcesses, false) }
+func PreemptiveLCFSPolicy(numProcesses int) policy { return newStackPolicy(numProcesses, true) }
+
+func (s *stackPolicy) push(proc *Process) {
+	// a preempted process has to go under the processes that arrived after it, so that the stack stays sorted from last to first
+	var newer []*Process
+	for !s.stack.Empty() && s.stack.Top().arriveTime > proc.arriveTime {
+		newer = append(newer, s.stack.Pop())
+	}
+	s.stack.Push(proc)
+	for i := len(newer) - 1; i >= 0; i-- {
+		s.stack.Push(newer[i])
+	}
+}
+func (s *stackPolicy) pop() *Process    { return s.stack.Pop() }
+func (s *stackPolicy) remove() *Process { return s.stack.Pop() }
+func (s *stackPolicy) peek() *Process   { return s.stack.Top() }
+func (s *stackPolicy) len() int         { return s.stack.Len() }
+func (s *stackPolicy) preempts(running *Process) bool {
+	return s.preemptive && s.peek().arriveTime > running.arriveTime
+}
+func (s *stackPolicy) quantum(*Process) uint32      { return 0 }
+func (s *stackPolicy) tick(*Process, uint32)        {}
+func (s *stackPolicy) nextDecision(*Process) uint32 { return 0 }
+
+// queuePolicy gives the cpu to the process that has been waiting in the queue the longest,
+// with a quantum this is round robin
+type queuePolicy struct {
+	// processes will be pushed into this queue as they arrive, and so they will naturally be sorted
+	// from first to last, which is perfect for FCFS
+	queue      *sim.Queue[*Process]
+	preemptive bool
+	q          uint32
+}
+
+func newQueuePolicy(len int, preemptive bool, quantum uint32) *queuePolicy {
+	return &queuePolicy{sim.NewQueue[*Process](len), preemptive, quantum}
+}
+
+func FCFSPolicy(numProcesses int) policy           { return newQueuePolicy(numProcesses, false, 0) }
+func PreemptiveFCFSPolicy(numProcesses int) policy { return newQueuePolicy(numProcesses, true, 0) }
+
+func RoundRobinPolicy(quantum uint32) Policy {
+	if quantum == 0 {
+		log.Panic("The round robin time quantum must be greater than zero")
+	}
+
+	return func(numProcesses int) policy {
+		return newQueuePolicy(numProcesses, false, quantum)
+	}
+}
+
+func (q *queuePolicy) push(proc *Process) { q.queue.Push(proc) }
+func (q *queuePolicy) pop() *Process      { return q.queue.Pop() }
+func (q *queuePolicy) remove() *Process   { return q.queue.Pop() }
+func (q *queuePolicy) peek() *Process     { return q.queue.Front() }
+func (q *queuePolicy) len() int           { return q.queue.Len() }
+func (q *queuePolicy) preempts(running *Process) bool {
+	return q.preemptive && q.peek().arriveTime < running.arriveTime
+}
+func (q *queuePolicy) quantum(*Process) uint32      { return q.q }
+func (q *queuePolicy) tick(*Process, uint32)        {}
+func (q *queuePolicy) nextDecision(*Process) uint32 { return 0 }
+
+// heapPolicy gives the cpu to the process that is the smallest according to the heap's less function
+type heapPolicy struct {
+	// I'm using a heap here because the time complexity for heapify is way better than sort
+	// and we only need to know what the smallest process is
+	heap       *Heap
+	preemptive bool
+}
+
+func newHeapPolicy(len int, preemptive bool, less func(a, b *Process) bool) *heapPolicy {
+	return &heapPolicy{NewHeap(len, less), preemptive}
+}
+
+func (h *heapPolicy) push(proc *Process) { heap.Push(h.heap, proc) }
+func (h *heapPolicy) pop() *Process      { return heap.Pop(h.heap).(*Process) }
+func (h *heapPolicy) remove() *Process   { return heap.Pop(h.heap).(*Process) }
+func (h *heapPolicy) peek() *Process     { return h.heap.Top() }
+func (h *heapPolicy) len() int           { return h.heap.Len() }
+func (h *heapPolicy) preempts(running *Process) bool {
+	return h.preemptive && h.heap.less(h.peek(), running)
+}
+func (h *heapPolicy) quantum(*Process) uint32      { return 0 }
+func (h *heapPolicy) tick(*Process, uint32)        {}
+func (h *heapPolicy) nextDecision(*Process) uint32 { return 0 }
+
+// shorterJob is the less function for SJF
+func shorterJob(a, b *Process) bool {
+	return a.executionTimeLeft < b.executionTimeLeft
+}
+
+func SJFPolicy(numProcesses int) policy { return newHeapPolicy(numProcesses, false, shorterJob) }
+func PreemptiveSJFPolicy(numProcesses int) policy {
+	return newHeapPolicy(numProcesses, true, shorterJob)
+}
+
+// predictiveSJFPolicy is SJF without knowing the execution time ahead, it predicts the length of the next cpu burst of every process
+// by exponential averaging of it's past bursts, prediction = alpha * last burst + (1 - alpha) * last prediction,
+// the predictions are kept in the processes, so that they follow them when they move between cores
+type predictiveSJFPolicy struct {
+	*heapPolicy
+	alpha        float64
+	initialGuess float64
+}
+
+func newPredictiveSJFPolicy(len int, preemptive bool, alpha, initialGuess float64) *predictiveSJFPolicy {
+	p := &predictiveSJFPolicy{alpha: alpha, initialGuess: initialGuess}
+	p.heapPolicy = newHeapPolicy(len, preemptive, func(a, b *Process) bool {
+		if ra, rb := predictedLeft(a), predictedLeft(b); ra != rb {
+			return ra < rb
+		}
+		return a.arriveTime < b.arriveTime
+	})
+	return p
+}
+
+func checkPrediction(alpha, initialGuess float64) {
+	if alpha < 0 || alpha > 1 {
+		log.Panic("The exponential averaging alpha has to be between 0 and 1")
+	}
+	if initialGuess < 0 {
+		log.Panic("The initial burst prediction cannot be negative")
+	}
+}
+
+func PredictiveSJFPolicy(alpha, initialGuess float64) Policy {
+	checkPrediction(alpha, initialGuess)
+	return func(numProcesses int) policy {
+		return newPredictiveSJFPolicy(numProcesses, false, alpha, initialGuess)
+	}
+}
+func PreemptivePredictiveSJFPolicy(alpha, initialGuess float64) Policy {
+	checkPrediction(alpha, initialGuess)
+	return func(numProcesses int) policy {
+		return newPredictiveSJFPolicy(numProcesses, true, alpha, initialGuess)
+	}
+}
+
+// ranInBurst returns for how long proc has been executing in it's current cpu burst
+func ranInBurst(proc *Process) uint32 {
+	if len(proc.bursts) == 0 {
+		return proc.executionTime - proc.executionTimeLeft
+	}
+	return proc.burstTime
+}
+
+// predictedLeft returns how much longer the current cpu burst of proc is predicted to take,
+// a burst that already took longer than predicted is expected to end any moment
+func predictedLeft(proc *Process) float64 {
+	return max(0, proc.predictedBurst-float64(ranInBurst(proc)))
+}
+
+func (p *predictiveSJFPolicy) push(proc *Process) {
+	// the first prediction is only made when the process arrives, after that it only changes when a cpu burst ends
+	if proc.burst == 0 && proc.executionTimeLeft == proc.executionTime {
+		proc.predictedBurst = p.initialGuess
+	}
+	p.heapPolicy.push(proc)
+}
+
+func (p *predictiveSJFPolicy) tick(running *Process, elapsed uint32) {
+	// the cpu burst is over when the process is done, or has moved on to an i/o burst
+	if running == nil || (running.executionTimeLeft != 0 && running.burst%2 == 0) {
+		return
+	}
+	// the burst that just ended is the one before the current one, or the only one
+	length, finished := running.executionTime, uint32(1)
+	if len(running.bursts) != 0 {
+		length, finished = running.bursts[running.burst-1], (running.burst+1)/2
+	}
+	// the error is the mean over all the finished cpu bursts, so the new one is added to it with the weight of one of them
+	running.predictionError += (math.Abs(float64(length)-running.predictedBurst) - running.predictionError) / float64(finished)
+	running.predictedBurst = p.alpha*float64(length) + (1-p.alpha)*running.predictedBurst
+}
+
+// hrrnPolicy gives the cpu to the process with the highest response ratio, (wait + service) / service,
+// which favours short jobs like SJF, but lets long jobs catch up the longer they wait
+type hrrnPolicy struct {
+	// the ratios of the waiting processes change as time passes, so a single heap would have to be fixed before every decision,
+	// but processes with the same execution time always stay in the order they arrived in,
+	// so they are kept in a heap for every execution time, and only the first one of every heap is compared
+	heaps   map[uint32]*Heap
+	waiting int
+	time    uint32
+}
+
+func newHRRNPolicy(int) *hrrnPolicy { return &hrrnPolicy{heaps: make(map[uint32]*Heap)} }
+
+func HRRNPolicy(numProcesses int) policy { return newHRRNPolicy(numProcesses) }
+
+// arrivedFirst is the order of the processes with the same execution time, the ids make it the same no matter the order they were pushed in
+func arrivedFirst(a, b *Process) bool {
+	if a.arriveTime != b.arriveTime {
+		return a.arriveTime < b.arriveTime
+	}
+	return a.id < b.id
+}
+
+// higherRatio reports whether a has a higher response ratio than b
+func (h *hrrnPolicy) higherRatio(a, b *Process) bool {
+	// the ratios are compared multiplied out, so that we do not have to deal with floats,
+	// the products can take up more than 64 bits, so we keep their high and low halves
+	hiA, loA := bits.Mul64(uint64(h.time-a.arriveTime)+uint64(a.executionTime), uint64(b.executionTime))
+	hiB, loB := bits.Mul64(uint64(h.time-b.arriveTime)+uint64(b.executionTime), uint64(a.executionTime))
+	if hiA != hiB {
+		return hiA > hiB
+	}
+	if loA != loB {
+		return loA > loB
+	}
+	return arrivedFirst(a, b)
+}
+
+func (h *hrrnPolicy) push(proc *Process) {
+	same, ok := h.heaps[proc.executionTime]
+	if !ok {
+		same = NewHeap(1, arrivedFirst)
+		h.heaps[proc.executionTime] = same
+	}
+	heap.Push(same, proc)
+	h.waiting++
+}
+func (h *hrrnPolicy) pop() *Process {
+	executionTime := h.peek().executionTime
+	same := h.heaps[executionTime]
+	proc := heap.Pop(same).(*Process)
+	if same.Len() == 0 {
+		delete(h.heaps, executionTime)
+	}
+	h.waiting--
+	return proc
+}
+func (h *hrrnPolicy) remove() *Process { return h.pop() }
+func (h *hrrnPolicy) peek() (highest *Process) {
+	for _, same := range h.heaps {
+		if highest == nil || h.higherRatio(same.Top(), highest) {
+			highest = same.Top()
+		}
+	}
+	return highest
+}
+func (h *hrrnPolicy) len() int                        { return h.waiting }
+func (h *hrrnPolicy) preempts(*Process) bool          { return false }
+func (h *hrrnPolicy) quantum(*Process) uint32         { return 0 }
+func (h *hrrnPolicy) tick(_ *Process, elapsed uint32) { h.time += elapsed }
+func (h *hrrnPolicy) nextDecision(*Process) uint32    { return 0 }
+
+// priorityPolicy gives the cpu to the most important process, and with aging,
+// raises the priority of processes the longer they wait, so that unimportant ones do not starve
+type priorityPolicy struct {
+	*heapPolicy
+	agingRate uint32
+	time      uint64
+	// when each waiting process was last pushed, it has been aging since then
+	pushed map[*Process]uint64
+	// how many priority levels each process gained while it waited, a process that got the cpu keeps the priority it aged to
+	aged map[*Process]uint32
+	// where every waiting process is in the heap, so that the ones that aged can be moved up without fixing the whole heap
+	index map[*Process]int
+	// the next time every waiting process is going to age, in the order they are going to age in,
+	// since all of them age at the same rate, a process that aged is queued up again at the back, and the queue stays sorted,
+	// even when a long step lets some of them age more than once
+	agings *sim.Queue[*aging]
+	// the entry of every waiting process in agings, the ones of processes that got the cpu before they aged are skipped
+	nextAging map[*Process]*aging
+}
+
+type aging struct {
+	proc *Process
+	at   uint64
+}
+
+// indexedHeap is the heap of the priority policy, that keeps the index of every process up to date
+type indexedHeap struct {
+	*Heap
+	index map[*Process]int
+}
+
+func (h indexedHeap) Swap(i, j int) {
+	h.Heap.Swap(i, j)
+	h.index[h.processes[i]], h.index[h.processes[j]] = i, j
+}
+func (h indexedHeap) Push(x any) {
+	h.index[x.(*Process)] = h.Len()
+	h.Heap.Push(x)
+}
+func (h indexedHeap) Pop() any {
+	proc := h.Heap.Pop().(*Process)
+	delete(h.index, proc)
+	return proc
+}
+
+func newPriorityPolicy(len int, preemptive bool, agingRate uint32) *priorityPolicy {
+	p := &priorityPolicy{agingRate: agingRate, pushed: make(map[*Process]uint64, len), aged: make(map[*Process]uint32, len),
+		index: make(map[*Process]int, len), agings: sim.NewQueue[*aging](len), nextAging: make(map[*Process]*aging, len)}
+	p.heapPolicy = newHeapPolicy(len, preemptive, func(a, b *Process) bool {
+		if pa, pb := p.effectivePriority(a), p.effectivePriority(b); pa != pb {
+			return pa < pb
+		}
+		// processes with the same priority are executed in the order they arrived, the ids make the order the same
+		// no matter how many times the heap was fixed while they waited
+		return arrivedFirst(a, b)
+	})
+	return p
+}
+
+func PriorityPolicy(agingRate uint32) Policy {
+	return func(numProcesses int) policy {
+		return newPriorityPolicy(numProcesses, false, agingRate)
+	}
+}
+func PreemptivePriorityPolicy(agingRate uint32) Policy {
+	return func(numProcesses int) policy {
+		return newPriorityPolicy(numProcesses, true, agingRate)
+	}
+}
+
+// effectivePriority returns the priority of proc after aging
+func (p *priorityPolicy) effectivePriority(proc *Process) uint32 {
+	return proc.priority - min(proc.priority, p.aged[proc])
+}
+
+func (p *priorityPolicy) indexed() indexedHeap { return indexedHeap{p.heap, p.index} }
+
+// scheduleAging queues up the next time proc is going to age after it last aged, or was pushed, at last, if it still can
+func (p *priorityPolicy) scheduleAging(proc *Process, last uint64) {
+	if p.agingRate == 0 || p.effectivePriority(proc) == 0 || last-p.pushed[proc]+uint64(p.agingRate) > math.MaxUint32 {
+		delete(p.nextAging, proc)
+		return
+	}
+	next := &aging{proc, last + uint64(p.agingRate)}
+	p.nextAging[proc] = next
+	p.agings.Push(next)
+}
+
+// skipStale drops the agings of processes that are not waiting anymore, or were pushed again since, from the front of the queue
+func (p *priorityPolicy) skipStale() {
+	for !p.agings.Empty() && p.nextAging[p.agings.Front().proc] != p.agings.Front() {
+		p.agings.Pop()
+	}
+}
+
+func (p *priorityPolicy) push(proc *Process) {
+	// a process that had the cpu starts aging from it's own priority again
+	p.pushed[proc], p.aged[proc] = p.time, 0
+	heap.Push(p.indexed(), proc)
+	p.scheduleAging(proc, p.time)
+}
+func (p *priorityPolicy) pop() *Process {
+	proc := heap.Pop(p.indexed()).(*Process)
+	delete(p.pushed, proc)
+	delete(p.nextAging, proc)
+	return proc
+}
+func (p *priorityPolicy) remove() *Process { return p.pop() }
+
+func (p *priorityPolicy) tick(_ *Process, elapsed uint32) {
+	p.time += uint64(elapsed)
+	// only the processes that gained a priority level have to be moved up the heap, one level at a time,
+	// so that there is never more than one process out of place in it
+	for p.skipStale(); !p.agings.Empty() && p.agings.Front().at <= p.time; p.skipStale() {
+		next := p.agings.Pop()
+		p.aged[next.proc]++
+		heap.Fix(p.indexed(), p.index[next.proc])
+		p.scheduleAging(next.proc, next.at)
+	}
+}
+
+// a waiting process can only preempt the running one when it gains a priority level,
+// so the next decision is when the first of them is going to age
+func (p *priorityPolicy) nextDecision(*Process) uint32 {
+	if !p.preemptive {
+		return 0
+	}
+	p.skipStale()
+	if p.agings.Empty() {
+		return 0
+	}
+	return uint32(p.agings.Front().at - p.time)
+}
+
+// mlfqPolicy keeps a round robin queue for every level, and always gives the cpu to a process from the most important non empty one
+type mlfqPolicy struct {
+	queues        []*sim.Queue[*Process]
+	quanta        []uint32
+	boostInterval uint32
+	// how long it has been since the last priority boost
+	sinceBoost uint32
+	// for how long the process has been executing since it last got the cpu
+	ran map[*Process]uint32
+}
+
+func newMLFQPolicy(numProcesses int, quanta []uint32, boostInterval uint32) *mlfqPolicy {
+	queues := make([]*sim.Queue[*Process], 0, len(quanta))
+	for range quanta {
+		queues = append(queues, sim.NewQueue[*Process](numProcesses))
+	}
+	return &mlfqPolicy{queues: queues, quanta: quanta, boostInterval: boostInterval, ran: make(map[*Process]uint32, numProcesses)}
+}
+
+func MLFQPolicy(quanta []uint32, boostInterval uint32) Policy {
+	if len(quanta) == 0 {
+		log.Panic("MLFQ needs at least one queue")
+	}
+	if len(quanta) > math.MaxUint32 {
+		log.Panicf("MLFQ cannot have more than %d queues", math.MaxUint32)
+	}
+	if slices.Contains(quanta, 0) {
+		log.Panic("The MLFQ time quanta must be greater than zero")
+	}
+
+	// the caller could change the slice after getting the Policy, so we keep our own copy
+	quanta = slices.Clone(quanta)
+	return func(numProcesses int) policy {
+		return newMLFQPolicy(numProcesses, quanta, boostInterval)
+	}
+}
+
+func (m *mlfqPolicy) push(proc *Process) {
+	// a process that used up it's quantum goes down a queue, one that got preempted stays where it was
+	if m.ran[proc] >= m.quanta[proc.queueLevel] && int(proc.queueLevel) < len(m.queues)-1 {
+		proc.queueLevel++
+		proc.demotions++
+	}
+	m.ran[proc] = 0
+	m.queues[proc.queueLevel].Push(proc)
+}
+func (m *mlfqPolicy) pop() *Process {
+	for _, queue := range m.queues {
+		if !queue.Empty() {
+			return queue.Pop()
+		}
+	}
+	log.Panic("cannot pop from an empty MLFQ")
+	return nil
+}
+func (m *mlfqPolicy) remove() *Process { return m.pop() }
+func (m *mlfqPolicy) peek() *Process {
+	for _, queue := range m.queues {
+		if !queue.Empty() {
+			return queue.Front()
+		}
+	}
+	log.Panic("cannot peek into an empty MLFQ")
+	return nil
+}
+func (m *mlfqPolicy) len() (res int) {
+	for _, queue := range m.queues {
+		res += queue.Len()
+	}
+	return res
+}
+func (m *mlfqPolicy) preempts(running *Process) bool {
+	return m.peek().queueLevel < running.queueLevel
+}
+func (m *mlfqPolicy) quantum(proc *Process) uint32 { return m.quanta[proc.queueLevel] }
+func (m *mlfqPolicy) tick(running *Process, elapsed uint32) {
+	if running != nil {
+		m.ran[running] += elapsed
+	}
+	if m.boostInterval == 0 {
+		return
+	}
+	// an idle cpu can go past a few boosts at once, but they do not do anything when there are no processes
+	sinceBoost := uint64(m.sinceBoost) + uint64(elapsed)
+	m.sinceBoost = uint32(sinceBoost % uint64(m.boostInterval))
+	if sinceBoost < uint64(m.boostInterval) {
+		return
+	}
+
+	// every process goes back to the first queue, in the order of the queues they were in, so that nobody starves
+	if running != nil {
+		running.queueLevel = 0
+		m.ran[running] = 0
+	}
+	for _, queue := range m.queues[1:] {
+		for !queue.Empty() {
+			proc := queue.Pop()
+			proc.queueLevel = 0
+			m.queues[0].Push(proc)
+		}
+	}
+}
+
+// the boost changes the quantum of the running process
+func (m *mlfqPolicy) nextDecision(*Process) uint32 {
+	if m.boostInterval == 0 {
+		return 0
+	}
+	return m.boostInterval - m.sinceBoost
+}
+
+// lotteryPolicy draws a random ticket out of the tickets of all waiting processes, and gives the cpu to it's holder
+type lotteryPolicy struct {
+	waiting []*Process
+	// the sum of the tickets of all the waiting processes
+	total uint64
+	q     uint32
+	seed  uint64
+	rand  *rand.Rand
+	// the index of the process that won the last draw, it is kept so that peek and pop return the same process, -1 if there was no draw
+	winner int
+}
+
+func newLotteryPolicy(len int, quantum uint32, seed uint64) *lotteryPolicy {
+	return &lotteryPolicy{
+		waiting: make([]*Process, 0, len),
+		q:       quantum,
+		seed:    seed,
+		rand:    rand.New(rand.NewPCG(seed, 0)),
+		winner:  -1}
+}
+
+// onCore gives every core it's own stream of the seed, otherwise all of them would draw the same tickets
+func (l *lotteryPolicy) onCore(core uint32) {
+	l.rand = rand.New(rand.NewPCG(l.seed, uint64(core)))
+}
+
+func LotteryPolicy(quantum uint32, seed uint64) Policy {
+	if quantum == 0 {
+		log.Panic("The lottery time quantum must be greater than zero")
+	}
+
+	return func(numProcesses int) policy {
+		return newLotteryPolicy(numProcesses, quantum, seed)
+	}
+}
+
+func (l *lotteryPolicy) push(proc *Process) {
+	if proc.tickets == 0 {
+		log.Panic("A process without tickets can never win the lottery")
+	}
+
+	l.waiting = append(l.waiting, proc)
+	l.total += uint64(proc.tickets)
+	// the new tickets have to take part in the draw
+	l.winner = -1
+}
+func (l *lotteryPolicy) pop() *Process {
+	l.draw()
+	proc := l.waiting[l.winner]
+	l.waiting = slices.Delete(l.waiting, l.winner, l.winner+1)
+	l.total -= uint64(proc.tickets)
+	l.winner = -1
+	return proc
+}
+func (l *lotteryPolicy) remove() *Process { return l.pop() }
+func (l *lotteryPolicy) peek() *Process {
+	l.draw()
+	return l.waiting[l.winner]
+}
+
+// draw picks the winner, if it has not been picked already
+func (l *lotteryPolicy) draw() {
+	if len(l.waiting) == 0 {
+		log.Panic("cannot draw a lottery without any waiting processes")
+	}
+	if l.winner != -1 {
+		return
+	}
+
+	ticket := l.rand.Uint64N(l.total)
+	for i, proc := range l.waiting {
+		if ticket < uint64(proc.tickets) {
+			l.winner = i
+			return
+		}
+		ticket -= uint64(proc.tickets)
+	}
+}
+func (l *lotteryPolicy) len() int { return len(l.waiting) }
+
+// the lottery never preempts, so that it does not draw more often than it gives away the cpu
+func (l *lotteryPolicy) preempts(*Process) bool       { return false }
+func (l *lotteryPolicy) quantum(*Process) uint32      { return l.q }
+func (l *lotteryPolicy) tick(*Process, uint32)        {}
+func (l *lotteryPolicy) nextDecision(*Process) uint32 { return 0 }
+
+// STRIDE1 is divided by the amount of tickets to get the stride of a process, it is large so that the integer division stays accurate
+const STRIDE1 = 1 << 20
+
+// stridePolicy gives the cpu to the process with the smallest pass, and advances the pass of the running process by it's stride
+// for every unit of time it executes, the stride is inversely proportional to the tickets, so processes with more tickets run more often
+type stridePolicy struct {
+	*heapPolicy
+	q    uint32
+	pass map[*Process]uint64
+	// the pass of the last process that got the cpu, new processes start from it,
+	// so that they do not get the cpu to themselves until they catch up with the ones that were there before them
+	globalPass uint64
+}
+
+func newStridePolicy(len int, quantum uint32) *stridePolicy {
+	s := &stridePolicy{q: quantum, pass: make(map[*Process]uint64, len)}
+	s.heapPolicy = newHeapPolicy(len, false, func(a, b *Process) bool {
+		if s.pass[a] != s.pass[b] {
+			return s.pass[a] < s.pass[b]
+		}
+		return a.arriveTime < b.arriveTime
+	})
+	return s
+}
+
+func StridePolicy(quantum uint32) Policy {
+	if quantum == 0 {
+		log.Panic("The stride time quantum must be greater than zero")
+	}
+
+	return func(numProcesses int) policy {
+		return newStridePolicy(numProcesses, quantum)
+	}
+}
+
+func (s *stridePolicy) push(proc *Process) {
+	if proc.tickets == 0 {
+		log.Panic("A process without tickets has an infinite stride")
+	}
+	// a process coming back from i/o has fallen behind, so it catches up to the others the same way a new one would
+	s.pass[proc] = max(s.pass[proc], s.globalPass)
+	s.heapPolicy.push(proc)
+}
+func (s *stridePolicy) pop() *Process {
+	proc := s.heapPolicy.pop()
+	s.globalPass = s.pass[proc]
+	return proc
+}
+func (s *stridePolicy) quantum(*Process) uint32 { return s.q }
+func (s *stridePolicy) tick(running *Process, elapsed uint32) {
+	if running != nil {
+		s.pass[running] += STRIDE1 / uint64(running.tickets) * uint64(elapsed)
+	}
+}
+
+// MAX_NICE is the largest nice value, the smallest is -MAX_NICE - 1, just like in linux
+const MAX_NICE = 19
+
+// NICE_0_WEIGHT is the weight of a process with a nice value of 0, for which vruntime advances at the same rate as time
+const NICE_0_WEIGHT = 1024
+
+// VRUNTIME_SCALE is how much vruntime a process with a nice value of 0 gets for every unit of time it executes,
+// vruntime is a whole number, so that it adds up to exactly the same value no matter how long the steps are,
+// and it is big enough that rounding it down for the heaviest weight is off by less than a thousandth of a percent
+const VRUNTIME_SCALE = 1 << 20
+
+// niceToWeight is the table linux uses to turn nice values from -20 to 19 into weights,
+// every nice level is about a 10% difference in the share of the cpu
+var niceToWeight = [...]uint64{
+	/* -20 */ 88761, 71755, 56483, 46273, 36291,
+	/* -15 */ 29154, 23254, 18705, 14949, 11916,
+	/* -10 */ 9548, 7620, 6100, 4904, 3906,
+	/*  -5 */ 3121, 2501, 1991, 1586, 1277,
+	/*   0 */ 1024, 820, 655, 526, 423,
+	/*   5 */ 335, 272, 215, 172, 137,
+	/*  10 */ 110, 87, 70, 56, 45,
+	/*  15 */ 36, 29, 23, 18, 15,
+}
+
+func weight(proc *Process) uint64 {
+	if proc.nice < -MAX_NICE-1 || proc.nice > MAX_NICE {
+		log.Panicf("The nice value of a process has to be between %d and %d", -MAX_NICE-1, MAX_NICE)
+	}
+	return niceToWeight[int(proc.nice)+MAX_NICE+1]
+}
+
+// vruntimeDelta is how much vruntime proc gets for every unit of time it executes
+func vruntimeDelta(proc *Process) uint64 {
+	return NICE_0_WEIGHT * VRUNTIME_SCALE / weight(proc)
+}
+
+// cfsPolicy keeps the ready processes in a heap sorted by vruntime, and gives the cpu to the one that got the least of it so far
+type cfsPolicy struct {
+	*heapPolicy
+	targetLatency  uint32
+	minGranularity uint32
+	// the smallest vruntime seen so far, it only ever grows, new processes start from it,
+	// so that they do not get the cpu to themselves until they catch up with the ones that were there before them
+	minVruntime uint64
+	// the slice of every process, calculated when it gets the cpu, so that it stays the same while the process is running,
+	// even when the processes waiting behind it change
+	slices map[*Process]uint32
+	// the processes that have already been given a starting vruntime
+	seen map[*Process]bool
+	// the sum of the weights of the waiting processes, kept up to date as they come and go
+	totalWeight uint64
+}
+
+func newCFSPolicy(len int, targetLatency, minGranularity uint32) *cfsPolicy {
+	c := &cfsPolicy{targetLatency: targetLatency, minGranularity: minGranularity,
+		slices: make(map[*Process]uint32, len), seen: make(map[*Process]bool, len)}
+	c.heapPolicy = newHeapPolicy(len, false, func(a, b *Process) bool {
+		if a.vruntime != b.vruntime {
+			return a.vruntime < b.vruntime
+		}
+		return a.arriveTime < b.arriveTime
+	})
+	return c
+}
+
+func CFSPolicy(targetLatency, minGranularity uint32) Policy {
+	if targetLatency == 0 {
+		log.Panic("The CFS target latency must be greater than zero")
+	}
+	if minGranularity == 0 {
+		log.Panic("The CFS minimum granularity must be greater than zero")
+	}
+
+	return func(numProcesses int) policy {
+		return newCFSPolicy(numProcesses, targetLatency, minGranularity)
+	}
+}
+
+func (c *cfsPolicy) push(proc *Process) {
+	if !c.seen[proc] {
+		c.seen[proc] = true
+		proc.vruntime = max(proc.vruntime, c.minVruntime)
+	} else {
+		// like in linux, a process coming back from i/o gets at most half of the target latency of credit for the time it slept,
+		// which lets interactive processes get the cpu quickly, without letting them keep it to themselves
+		if credit := uint64(c.targetLatency) * VRUNTIME_SCALE / 2; c.minVruntime > credit {
+			proc.vruntime = max(proc.vruntime, c.minVruntime-credit)
+		}
+	}
+	c.totalWeight += weight(proc)
+	c.heapPolicy.push(proc)
+}
+func (c *cfsPolicy) pop() *Process {
+	proc := c.heapPolicy.pop()
+	// every ready process should get the cpu once during the target latency, the heavier ones for longer
+	c.slices[proc] = max(c.minGranularity, uint32(uint64(c.targetLatency)*weight(proc)/c.totalWeight))
+	c.totalWeight -= weight(proc)
+	return proc
+}
+func (c *cfsPolicy) remove() *Process {
+	proc := c.heapPolicy.remove()
+	c.totalWeight -= weight(proc)
+	return proc
+}
+func (c *cfsPolicy) preempts(running *Process) bool {
+	return c.peek().vruntime+uint64(c.minGranularity)*VRUNTIME_SCALE < running.vruntime
+}
+func (c *cfsPolicy) quantum(proc *Process) uint32 { return c.slices[proc] }
+func (c *cfsPolicy) tick(running *Process, elapsed uint32) {
+	if running == nil {
+		return
+	}
+	running.vruntime += uint64(elapsed) * vruntimeDelta(running)
+
+	smallest := running.vruntime
+	if c.len() != 0 {
+		smallest = min(smallest, c.peek().vruntime)
+	}
+	c.minVruntime = max(c.minVruntime, smallest)
+}
+
+// the running process gets preempted once it's vruntime goes past the smallest waiting one by more than minGranularity
+func (c *cfsPolicy) nextDecision(running *Process) uint32 {
+	if running == nil || c.len() == 0 {
+		return 0
+	}
+	preemptedAfter := c.peek().vruntime + uint64(c.minGranularity)*VRUNTIME_SCALE
+	if preemptedAfter < running.vruntime {
+		return 1
+	}
+	// the first unit of time after which the vruntime of the running process is past preemptedAfter
+	next := (preemptedAfter-running.vruntime)/vruntimeDelta(running) + 1
+	if next >= math.MaxUint32 {
+		return 0
+	}
+	return uint32(next)
+}
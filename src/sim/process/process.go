@@ -4,43 +4,121 @@ import (
 	"cmp"
 	"fmt"
 	"log"
+	"math"
 	"math/rand/v2"
 	"reflect"
 	"slices"
 )
 
 type Process struct {
-	id                uint16
-	arriveTime        uint16
-	executionTime     uint16
-	executionTimeLeft uint16
-	waitTime          uint16
+	id            uint32
+	arriveTime    uint32
+	executionTime uint32
+	// priority is only used by the priority schedulers, a lower value means a more important process
+	priority uint32
+	// tickets is only used by the proportional share schedulers, the more tickets, the bigger share of the cpu a process should get
+	tickets uint32
+	// nice is only used by CFS, like in linux a lower value gives the process a bigger weight, and so a bigger share of the cpu
+	nice int8
+	// addressSpace is the address space the process runs in, processes that share one are like threads of the same program,
+	// and switching between them does not cost as much
+	addressSpace      uint32
+	executionTimeLeft uint32
+	// bursts are the alternating cpu and i/o bursts of the process, it starts and ends with a cpu burst,
+	// and the cpu bursts add up to executionTime, a process without bursts is a single cpu burst,
+	// they are never changed during a simulation, so copies of a process can share them
+	bursts []uint32
+	// burst is the index of the current burst, and burstTime is how long the process has spent in it
+	burst     uint32
+	burstTime uint32
+	// waitTime is the time the process spent ready, waiting for the cpu
+	waitTime uint32
+	// blockedTime is the time the process spent blocked, waiting for the i/o device and doing i/o
+	blockedTime uint32
+	// completionTime is when the process finished, and turnaroundTime is how long it took from arriving to finishing
+	completionTime uint32
+	turnaroundTime uint32
+	// responseTime is how long it took from arriving until the process first got to execute
+	responseTime uint32
+	// vruntime is only used by CFS, it is the execution time of the process, scaled down by it's weight,
+	// in units of 1/VRUNTIME_SCALE of time
+	vruntime uint64
+	// predictedBurst and predictionError are only used by the predictive SJF, they are the prediction for the next cpu burst,
+	// and the mean absolute difference between the lengths of the finished cpu bursts and their predictions
+	predictedBurst  float64
+	predictionError float64
+	// cpuShare is the part of the time between arriving and finishing that the process spent executing
+	cpuShare float64
+	// expectedShare is the part of the same time the process should have spent executing by it's tickets, like the proportional share
+	// schedulers try to give it, against the tickets of the other processes that were ready on the same cpu, so the closer
+	// the two shares are, the fairer the scheduler was, during the simulation it is the cpu time the process should have gotten so far
+	expectedShare float64
+	// queueLevel and demotions are only used by MLFQ, they are the queue the process finished in
+	// and how many times it was moved to a lower queue for using up it's whole quantum
+	queueLevel uint32
+	demotions  uint32
 }
 
-// Gen generates a slice of processes, sorted by arriveTime
-func Gen(num uint16, maxArriveTime uint16, maxExecutionTime uint16) *Slice {
-	if num == 0 {
+// GenConfig is what the processes generated by Gen are drawn from
+type GenConfig struct {
+	// Num is the number of processes, and their arrive times are drawn like Arrivals says, up to around MaxArriveTime
+	Num           uint32
+	MaxArriveTime uint32
+	Arrivals      Arrivals
+	// the cpu bursts are drawn from the Bursts distribution, and cut off at MaxExecutionTime, a nil Bursts draws them uniformly
+	MaxExecutionTime uint32
+	Bursts           Distribution
+	// every process gets a priority below PriorityLevels, between 1 and MaxTickets tickets, and a nice value up to MaxNice either way
+	PriorityLevels uint32
+	MaxTickets     uint32
+	MaxNice        uint8
+	// every process gets up to MaxIOBursts i/o bursts of at most MaxIOTime, each one between two cpu bursts
+	MaxIOBursts uint32
+	MaxIOTime   uint32
+	// the processes are spread over AddressSpaces address spaces, 0 gives every process it's own
+	AddressSpaces uint32
+}
+
+// Gen generates a slice of processes with random numbers from src, sorted by arriveTime, as config says
+func Gen(src rand.Source, config GenConfig) *Slice {
+	if config.Num == 0 {
 		log.Panic("Cannot generate 0 processes")
 	}
-	if maxExecutionTime == 0 {
+	if config.MaxExecutionTime == 0 {
 		log.Panic("Cannot generate processes with zero execution time")
 	}
+	if config.PriorityLevels == 0 {
+		log.Panic("Cannot generate processes with zero priority levels")
+	}
+	if config.MaxTickets == 0 {
+		log.Panic("Cannot generate processes with zero tickets")
+	}
+	if config.MaxNice > MAX_NICE {
+		log.Panicf("Cannot generate processes with a nice value above %d", MAX_NICE)
+	}
+	if config.MaxIOBursts != 0 && config.MaxIOTime == 0 {
+		log.Panic("Cannot generate i/o bursts with zero i/o time")
+	}
+	if (uint64(config.MaxIOBursts)+1)*uint64(config.MaxExecutionTime) > math.MaxUint32 {
+		log.Panicf("The execution time of a process with %d cpu bursts of up to %d cannot fit in 32 bits",
+			uint64(config.MaxIOBursts)+1, config.MaxExecutionTime)
+	}
+	if config.Bursts == nil {
+		config.Bursts = UniformBursts()
+	}
 
-	var processes Slice = make([]Process, num)
-	if maxArriveTime > 0 {
-		for i := range processes {
-			processes[i] = Process{id: uint16(i),
-				arriveTime:    uint16(rand.UintN(uint(maxArriveTime + 1))),
-				executionTime: uint16(1 + rand.UintN(uint(maxExecutionTime)))}
-			processes[i].executionTimeLeft = processes[i].executionTime
-		}
-	} else {
-		for i := range processes {
-			processes[i] = Process{id: uint16(i),
-				arriveTime:    0,
-				executionTime: uint16(1 + rand.UintN(uint(maxExecutionTime)))}
-			processes[i].executionTimeLeft = processes[i].executionTime
-		}
+	rng := rand.New(src)
+	arriveTimes := genArriveTimes(rng, config.Arrivals, config.Num, config.MaxArriveTime)
+	var processes Slice = make([]Process, config.Num)
+	for i := range processes {
+		processes[i] = Process{id: uint32(i),
+			arriveTime: arriveTimes[i],
+			priority:   uint32(rng.UintN(uint(config.PriorityLevels))),
+			tickets:    uint32(1 + rng.UintN(uint(config.MaxTickets))),
+			nice:       int8(rng.IntN(2*int(config.MaxNice)+1) - int(config.MaxNice))}
+		processes[i].executionTime, processes[i].bursts = genBursts(rng, config.MaxExecutionTime, config.Bursts, config.MaxIOBursts, config.MaxIOTime)
+		processes[i].executionTimeLeft = processes[i].executionTime
+		processes[i].addressSpace = genAddressSpace(rng, uint32(i), config.AddressSpaces)
 	}
 
 	slices.SortFunc(processes, func(a, b Process) int {
@@ -49,8 +127,66 @@ func Gen(num uint16, maxArriveTime uint16, maxExecutionTime uint16) *Slice {
 	return &processes
 }
 
+// genBursts generates the bursts of a single process and returns them with it's total execution time,
+// the cpu bursts are drawn from the given distribution, and the i/o bursts uniformly,
+// a process that got no i/o bursts is left as a single cpu burst
+func genBursts(rng *rand.Rand, maxExecutionTime uint32, distribution Distribution, maxIOBursts uint32, maxIOTime uint32) (executionTime uint32, bursts []uint32) {
+	ioBursts := uint32(rng.UintN(uint(maxIOBursts) + 1))
+	if ioBursts == 0 {
+		return distribution.draw(rng, maxExecutionTime), nil
+	}
+
+	bursts = make([]uint32, 2*int(ioBursts)+1)
+	for i := range bursts {
+		if i%2 == 0 {
+			bursts[i] = distribution.draw(rng, maxExecutionTime)
+			executionTime += bursts[i]
+		} else {
+			bursts[i] = uint32(1 + rng.UintN(uint(maxIOTime)))
+		}
+	}
+	return executionTime, bursts
+}
+
+// genAddressSpace picks a random one of the address spaces for the process with the given id,
+// or gives it it's own when there are none to share
+func genAddressSpace(rng *rand.Rand, id uint32, addressSpaces uint32) uint32 {
+	if addressSpaces == 0 {
+		return id
+	}
+	return uint32(rng.UintN(uint(addressSpaces)))
+}
+
+// advanceBurst moves the process elapsed units of time forward in it's current burst, which cannot go past it's end,
+// and reports whether the burst is over, which is never the case for a process that is a single cpu burst
+func (p *Process) advanceBurst(elapsed uint32) bool {
+	if len(p.bursts) == 0 {
+		return false
+	}
+	p.burstTime += elapsed
+	if p.burstTime < p.bursts[p.burst] {
+		return false
+	}
+	p.burst++
+	p.burstTime = 0
+	return true
+}
+
 type Slice []Process
 
+// MaxTimes returns the latest arrive time and the longest execution time of the processes
+func (s *Slice) MaxTimes() (maxArriveTime, maxExecutionTime uint32) {
+	if s == nil {
+		log.Panic("The slice to get the times of cannot be nil")
+	}
+
+	for _, proc := range *s {
+		maxArriveTime = max(maxArriveTime, proc.arriveTime)
+		maxExecutionTime = max(maxExecutionTime, proc.executionTime)
+	}
+	return maxArriveTime, maxExecutionTime
+}
+
 var processNumFields = reflect.TypeOf(Process{}).NumField()
 
 // Records implements the Recorder interface
@@ -97,45 +233,67 @@ func (s *Slice) Copy() *Slice {
 	return (*Slice)(&c)
 }
 
-// Heap implements the container.Heap.Interface to get a process min Heap sorted by execution time left (for SJF)
-type Heap []*Process
+// Heap implements the container.Heap.Interface to get a process min Heap sorted with the less function,
+// which lets every scheduler decide what the shortest or most important job is
+type Heap struct {
+	processes []*Process
+	less      func(a, b *Process) bool
+}
+
+// NewHeap returns a pointer to an initialized heap, with enough space for len processes
+func NewHeap(len int, less func(a, b *Process) bool) *Heap {
+	if less == nil {
+		log.Panic("The heap has to have a less function to sort processes with")
+	}
+
+	return &Heap{make([]*Process, 0, len), less}
+}
 
 func (h *Heap) Len() int {
 	if h == nil {
-		log.Panic("The underlying slice of a heap cannot be nil")
+		log.Panic("The heap pointer cannot be nil")
 	}
 
-	return len(*h)
+	return len(h.processes)
 }
 func (h *Heap) Less(i, j int) bool {
 	if h == nil {
-		log.Panic("The underlying slice of a heap cannot be nil")
+		log.Panic("The heap pointer cannot be nil")
 	}
 
-	return (*h)[i].executionTimeLeft < (*h)[j].executionTimeLeft
+	return h.less(h.processes[i], h.processes[j])
 }
 func (h *Heap) Swap(i, j int) {
 	if h == nil {
-		log.Panic("The underlying slice of a heap cannot be nil")
+		log.Panic("The heap pointer cannot be nil")
 	}
 
-	(*h)[i], (*h)[j] = (*h)[j], (*h)[i]
+	h.processes[i], h.processes[j] = h.processes[j], h.processes[i]
 }
 func (h *Heap) Push(x any) {
 	if h == nil {
-		log.Panic("The underlying slice of a heap cannot be nil")
+		log.Panic("The heap pointer cannot be nil")
 	}
 
-	*h = append(*h, x.(*Process))
+	h.processes = append(h.processes, x.(*Process))
 }
 func (h *Heap) Pop() any {
 	if h == nil {
-		log.Panic("The underlying slice of a heap cannot be nil")
+		log.Panic("The heap pointer cannot be nil")
 	}
 
-	old := *h
+	old := h.processes
 	n := len(old)
 	x := old[n-1]
-	*h = old[:n-1]
+	h.processes = old[:n-1]
 	return x
 }
+
+// Top is a convenience function to get the process at the top of the heap without the need to pop
+func (h *Heap) Top() *Process {
+	if h == nil {
+		log.Panic("The heap pointer cannot be nil")
+	}
+
+	return h.processes[0]
+}
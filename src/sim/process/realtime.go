@@ -0,0 +1,319 @@
+package process
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+)
+
+// Task is a periodic real-time task, every period it releases a job that needs wcet units of time,
+// and has to be done deadline units of time after being released
+type Task struct {
+	id       uint32
+	period   uint32
+	wcet     uint32
+	deadline uint32
+	// utilization is the part of the cpu the task needs, wcet / period
+	utilization float64
+	// the fields below are filled in by the simulation
+	jobs              uint32
+	deadlineMisses    uint32
+	worstResponseTime uint32
+	// rtaResponseTime is the worst case response time calculated with response time analysis, it is only filled in by RM,
+	// if the task is not schedulable it is the first value of the iteration that went past the deadline
+	rtaResponseTime uint32
+}
+
+// UTILIZATION_TOLERANCE is how far the utilization of a generated task set can be from the one it was generated for,
+// the wcets are whole units of time, so rounding them moves the utilization of the task set away from the one UUniFast split up
+const UTILIZATION_TOLERANCE = 0.01
+
+// MAX_TASK_SET_DRAWS is how many task sets GenTasks draws before it gives up on finding one within UTILIZATION_TOLERANCE
+const MAX_TASK_SET_DRAWS = 100000
+
+// GenTasks generates num periodic tasks with random numbers from src, with periods between minPeriod and maxPeriod, that together use utilization of the cpu,
+// give or take UTILIZATION_TOLERANCE, with constrainedDeadlines the deadlines are between the wcet and the period, otherwise they are equal to the period
+func GenTasks(src rand.Source, num uint32, minPeriod, maxPeriod uint32, utilization float64, constrainedDeadlines bool) *TaskSlice {
+	if num == 0 {
+		log.Panic("Cannot generate 0 tasks")
+	}
+	if minPeriod == 0 || minPeriod > maxPeriod {
+		log.Panic("The task periods have to be greater than zero, and minPeriod cannot be greater than maxPeriod")
+	}
+	if utilization <= 0 {
+		log.Panic("The task utilization has to be greater than zero")
+	}
+
+	rng := rand.New(src)
+	// the task sets that rounding moved too far away from the utilization are thrown away, and drawn again
+	for range MAX_TASK_SET_DRAWS {
+		tasks := genTaskSet(rng, num, minPeriod, maxPeriod, utilization, constrainedDeadlines)
+		var total float64
+		for _, task := range *tasks {
+			total += task.utilization
+		}
+		if math.Abs(total-utilization) <= UTILIZATION_TOLERANCE {
+			return tasks
+		}
+	}
+	log.Panicf("Could not generate %d tasks with a utilization of %g in %d tries, their periods are too short for the wcets to be rounded that close to it",
+		num, utilization, MAX_TASK_SET_DRAWS)
+	return nil
+}
+
+// genTaskSet draws a single task set for GenTasks, it's utilization is only around the given one
+func genTaskSet(rng *rand.Rand, num uint32, minPeriod, maxPeriod uint32, utilization float64, constrainedDeadlines bool) *TaskSlice {
+	var tasks TaskSlice = make([]Task, num)
+	// UUniFast splits the utilization between the tasks uniformly, so that the task sets are not biased towards any shape
+	sumU := utilization
+	for i := range tasks {
+		u := sumU
+		if i < len(tasks)-1 {
+			nextSumU := sumU * math.Pow(rng.Float64(), 1/float64(len(tasks)-1-i))
+			u = sumU - nextSumU
+			sumU = nextSumU
+		}
+
+		period := minPeriod + uint32(rng.UintN(uint(maxPeriod-minPeriod)+1))
+		// a task has to execute for at least one unit of time, and we cannot round up past the period
+		wcet := uint32(min(float64(period), max(1, math.Round(u*float64(period)))))
+		deadline := period
+		if constrainedDeadlines {
+			deadline = wcet + uint32(rng.UintN(uint(period-wcet)+1))
+		}
+		tasks[i] = Task{id: uint32(i), period: period, wcet: wcet, deadline: deadline,
+			utilization: float64(wcet) / float64(period)}
+	}
+	return &tasks
+}
+
+type TaskSlice []Task
+
+var taskNumFields = reflect.TypeOf(Task{}).NumField()
+
+// Records implements the Recorder interface
+func (s *TaskSlice) Records() (records [][]string) {
+	if s == nil {
+		log.Panic("The slice to get records from cannot be nil")
+	}
+	if len(*s) == 0 {
+		log.Panic("The slice to get records from cannot be empty")
+	}
+
+	records = make([][]string, len(*s)+1)
+	for i := range records {
+		records[i] = make([]string, taskNumFields)
+	}
+
+	for i := range records[0] {
+		records[0][i] = reflect.TypeOf(Task{}).Field(i).Name
+	}
+
+	vals := records[1:]
+	for i, task := range *s {
+		for j := range vals[i] {
+			field := fmt.Sprint(reflect.ValueOf(task).Field(j))
+			vals[i][j] = field
+		}
+	}
+	return records
+}
+
+// Name implements the Namer interface, so that the tasks are not saved under the name of the processes
+func (s *TaskSlice) Name() string {
+	return "task"
+}
+
+// Copy makes a deep copy of the passed in TaskSlice
+func (s *TaskSlice) Copy() *TaskSlice {
+	if s == nil {
+		log.Panic("The slice to copy cannot be nil")
+	}
+	if len(*s) == 0 {
+		log.Panic("The slice to copy cannot be empty")
+	}
+
+	c := slices.Clone(*s)
+	return &c
+}
+
+// Schedulability holds the results of the analytical schedulability tests of a task set,
+// so that they can be compared with the deadline misses from the simulation
+type Schedulability struct {
+	utilization float64
+	// density is the sum of wcet / deadline, it is the same as utilization for implicit deadlines
+	density float64
+	// liuLaylandBound is n(2^(1/n) - 1), RM can schedule every task set with a utilization up to it
+	liuLaylandBound float64
+	// rmUtilizationTest is sufficient, but not necessary, rmResponseTimeTest is exact
+	rmUtilizationTest  bool
+	rmResponseTimeTest bool
+	// edfTest is exact for deadlines equal to periods, and sufficient for constrained deadlines
+	edfTest bool
+}
+
+func (s Schedulability) String() string {
+	return fmt.Sprintf("utilization: %.4f"+
+		"\ndensity: %.4f"+
+		"\nLiu & Layland bound: %.4f"+
+		"\nRM utilization test passed: %t"+
+		"\nRM response time analysis passed: %t"+
+		"\nEDF test passed: %t",
+		s.utilization, s.density, s.liuLaylandBound, s.rmUtilizationTest, s.rmResponseTimeTest, s.edfTest)
+}
+
+// Analyze runs the schedulability tests for RM and EDF on the task set
+func Analyze(tasks *TaskSlice) (res Schedulability) {
+	if tasks == nil || len(*tasks) == 0 {
+		log.Panic("The task slice to analyze cannot be nil or empty")
+	}
+
+	n := float64(len(*tasks))
+	res.liuLaylandBound = n * (math.Pow(2, 1/n) - 1)
+	implicitDeadlines := true
+	for _, task := range *tasks {
+		res.utilization += task.utilization
+		res.density += float64(task.wcet) / float64(task.deadline)
+		implicitDeadlines = implicitDeadlines && task.deadline >= task.period
+	}
+	res.rmUtilizationTest = implicitDeadlines && res.utilization <= res.liuLaylandBound
+	res.rmResponseTimeTest = true
+	for _, task := range *responseTimeAnalysis(tasks) {
+		res.rmResponseTimeTest = res.rmResponseTimeTest && task.rtaResponseTime <= task.deadline
+	}
+	if implicitDeadlines {
+		res.edfTest = res.utilization <= 1
+	} else {
+		res.edfTest = res.density <= 1
+	}
+	return res
+}
+
+// responseTimeAnalysis returns a copy of the tasks with rtaResponseTime filled in for RM priorities,
+// the response time of a task is it's wcet plus all the jobs of shorter period tasks released while it is waiting,
+// which is iterated until it stops changing, or goes past the deadline
+func responseTimeAnalysis(tasks *TaskSlice) *TaskSlice {
+	res := tasks.Copy()
+	for i := range *res {
+		task := &(*res)[i]
+		response := uint64(task.wcet)
+		for {
+			next := uint64(task.wcet)
+			for _, other := range *res {
+				if rmBefore(&other, task) {
+					// every job of a more important task released during the response time delays this one
+					next += (response + uint64(other.period) - 1) / uint64(other.period) * uint64(other.wcet)
+				}
+			}
+			if next == response || next > uint64(task.deadline) {
+				response = next
+				break
+			}
+			response = next
+		}
+		task.rtaResponseTime = uint32(min(response, math.MaxUint32))
+	}
+	return res
+}
+
+// rmBefore reports whether a is more important than b under RM, shorter periods first, and lower ids to break ties
+func rmBefore(a, b *Task) bool {
+	if a.period != b.period {
+		return a.period < b.period
+	}
+	return a.id < b.id
+}
+
+type TaskAlg func(tasks *TaskSlice, horizon uint32) *TaskSlice
+
+// SimTasks runs a simulation of the tasks releasing jobs until horizon, using the strategies in the alg slice
+func SimTasks(tasks *TaskSlice, horizon uint32, algs ...TaskAlg) (res []*TaskSlice) {
+	res = make([]*TaskSlice, len(algs))
+
+	for i, alg := range algs {
+		res[i] = alg(tasks.Copy(), horizon)
+	}
+	return res
+}
+
+// EDF preemptively executes the job with the earliest absolute deadline
+func EDF(tasks *TaskSlice, horizon uint32) *TaskSlice {
+	return runTasks(tasks, horizon, func(owner map[*Process]*Task) func(a, b *Process) bool {
+		return func(a, b *Process) bool {
+			da := uint64(a.arriveTime) + uint64(owner[a].deadline)
+			db := uint64(b.arriveTime) + uint64(owner[b].deadline)
+			if da != db {
+				return da < db
+			}
+			return rmBefore(owner[a], owner[b])
+		}
+	})
+}
+
+// RM preemptively executes the job of the task with the shortest period, which is a fixed priority for every task
+func RM(tasks *TaskSlice, horizon uint32) *TaskSlice {
+	res := runTasks(tasks, horizon, func(owner map[*Process]*Task) func(a, b *Process) bool {
+		return func(a, b *Process) bool {
+			return rmBefore(owner[a], owner[b])
+		}
+	})
+	for i, task := range *responseTimeAnalysis(res) {
+		(*res)[i].rtaResponseTime = task.rtaResponseTime
+	}
+	return res
+}
+
+// runTasks turns the tasks into jobs released until horizon, runs them with a preemptive heap policy sorted by the less function,
+// and counts the deadline misses of every task, a job that misses it's deadline still runs until it is done
+func runTasks(tasks *TaskSlice, horizon uint32, less func(owner map[*Process]*Task) func(a, b *Process) bool) *TaskSlice {
+	if tasks == nil || len(*tasks) == 0 {
+		log.Panic("The task slice to be simulated cannot be nil or empty")
+	}
+	if horizon == 0 {
+		log.Panic("The simulation horizon has to be greater than zero")
+	}
+
+	var jobs Slice
+	var jobTasks []int
+	for i, task := range *tasks {
+		if task.period == 0 || task.wcet == 0 || task.deadline == 0 {
+			log.Panic("The period, wcet and deadline of a task have to be greater than zero")
+		}
+		for release := uint64(0); release < uint64(horizon); release += uint64(task.period) {
+			jobs = append(jobs, Process{id: uint32(len(jobs)), arriveTime: uint32(release),
+				executionTime: task.wcet, executionTimeLeft: task.wcet})
+			jobTasks = append(jobTasks, i)
+		}
+	}
+	// the jobs have to be sorted by arriveTime for run, so we sort their indices and then put them in that order
+	order := make([]int, len(jobs))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(jobs[a].arriveTime, jobs[b].arriveTime)
+	})
+	sorted := make(Slice, len(jobs))
+	owner := make(map[*Process]*Task, len(jobs))
+	for i, j := range order {
+		sorted[i] = jobs[j]
+		owner[&sorted[i]] = &(*tasks)[jobTasks[j]]
+	}
+
+	run(&sorted, newHeapPolicy(len(sorted), true, less(owner)))
+
+	for i := range sorted {
+		job, task := &sorted[i], owner[&sorted[i]]
+		response := job.waitTime + job.executionTime
+		task.jobs++
+		task.worstResponseTime = max(task.worstResponseTime, response)
+		if response > task.deadline {
+			task.deadlineMisses++
+		}
+	}
+	return tasks
+}
@@ -0,0 +1,32 @@
+package process
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// the wcets are rounded to whole units of time, but the task sets still have to use the utilization they were generated for
+func TestGenTasksKeepsTheUtilization(t *testing.T) {
+	for _, c := range []struct {
+		num                  uint32
+		minPeriod, maxPeriod uint32
+		utilization          float64
+	}{{8, 4, 64, 0.8}, {3, 10, 100, 0.5}, {16, 8, 128, 0.95}, {4, 50, 1000, 0.3}} {
+		for seed := range uint64(64) {
+			for _, constrained := range []bool{false, true} {
+				tasks := GenTasks(rand.NewPCG(seed, 0), c.num, c.minPeriod, c.maxPeriod, c.utilization, constrained)
+				var total float64
+				for _, task := range *tasks {
+					if task.wcet == 0 || task.wcet > task.deadline || task.deadline > task.period {
+						t.Errorf("%+v seed %d: task %d has a wcet of %d, a deadline of %d and a period of %d", c, seed, task.id, task.wcet, task.deadline, task.period)
+					}
+					total += task.utilization
+				}
+				if math.Abs(total-c.utilization) > UTILIZATION_TOLERANCE {
+					t.Errorf("%+v seed %d: the task set has a utilization of %g", c, seed, total)
+				}
+			}
+		}
+	}
+}
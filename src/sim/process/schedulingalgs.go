@@ -2,14 +2,63 @@ package process
 
 import (
 	"cmp"
-	"container/heap"
+	"fmt"
 	"log"
+	"math"
 	"slices"
-	"src/sim"
 )
 
 type Alg func(processes *Slice) *Slice
 
+// NEVER is returned by the nextEvent functions when nothing is going to happen until something else does
+const NEVER = math.MaxUint64
+
+// Engine decides how a simulation moves through time
+type Engine uint8
+
+const (
+	// Events jumps straight from one event to the next, like an arrival, a completion or the end of a quantum,
+	// so that long bursts and idle gaps take no longer to simulate than short ones,
+	// when something happens almost every unit of time it does the same work as Ticks, and is not any faster
+	Events Engine = iota
+	// Ticks moves through time one unit at a time, it is much slower, but simple enough to check the Events engine against,
+	// both engines give the same results
+	Ticks
+)
+
+var engineNames = [...]string{"events", "ticks"}
+
+func (e Engine) String() string {
+	if int(e) >= len(engineNames) {
+		return fmt.Sprint("Engine(", uint8(e), ")")
+	}
+	return engineNames[e]
+}
+
+// ParseEngine returns the Engine with the given name, as returned by String
+func ParseEngine(name string) (e Engine, ok bool) {
+	i := slices.Index(engineNames[:], name)
+	if i == -1 {
+		return Events, false
+	}
+	return Engine(i), true
+}
+
+// step returns for how many units of time the engine lets the simulation run, when it is at time and the next event is next units of time away,
+// it panics instead of letting the time of the simulation overflow
+func (e Engine) step(time uint32, next uint64) uint32 {
+	if next == NEVER {
+		log.Panic("The simulation has nothing left to wait for, but it is not done")
+	}
+	if e == Ticks {
+		next = 1
+	}
+	if uint64(time)+next > math.MaxUint32 {
+		log.Panicf("The simulation time cannot go past %d", uint32(math.MaxUint32))
+	}
+	return uint32(next)
+}
+
 // Sim runs a simulation of the given processes using the strategies in the alg slice
 func Sim(processes *Slice, algs ...Alg) (res []*Slice) {
 	res = make([]*Slice, len(algs))
@@ -20,234 +69,197 @@ func Sim(processes *Slice, algs ...Alg) (res []*Slice) {
 	return res
 }
 
-func PreemptiveLCFS(processes *Slice) *Slice {
-	if isSorted := slices.IsSortedFunc([]Process(*processes), func(a, b Process) int {
-		return cmp.Compare(a.arriveTime, b.arriveTime)
-	}); !isSorted {
-		log.Panic("The process scheduling algorithms have to receive a slice of Processes sorted by arriveTime")
+// Uniprocessor returns an Alg that simulates a single cpu scheduling with a new instance of the policy
+func Uniprocessor(newPolicy Policy) Alg {
+	return func(processes *Slice) *Slice {
+		return run(processes, newPolicy(len(*processes)))
+	}
+}
+
+// SimUniprocessor runs a simulation of the given processes on a single cpu, once for each of the policies,
+// switchCost and addressSpaceCost are the costs of a context switch, the second one only paid between address spaces,
+// the statistics and the timeline of the cpu, like the number of context switches, are returned next to the results of every run
+func SimUniprocessor(processes *Slice, engine Engine, switchCost, addressSpaceCost uint32, policies ...Policy) (res []*Slice, cpus []*CoreSlice, timelines []*Timeline) {
+	res = make([]*Slice, len(policies))
+	cpus = make([]*CoreSlice, len(policies))
+	timelines = make([]*Timeline, len(policies))
+
+	for i, newPolicy := range policies {
+		processes := processes.Copy()
+		res[i], cpus[i], timelines[i] = runUniprocessor(processes, newPolicy(len(*processes)), engine, switchCosts{switchCost, addressSpaceCost})
 	}
+	return res, cpus, timelines
+}
+
+// checkProcesses panics if the processes cannot be simulated
+func checkProcesses(processes *Slice) {
 	if *processes == nil {
 		log.Panic("The process slice to be simulated cannot be nil")
 	}
 	if len(*processes) == 0 {
 		log.Panic("The process slice to be simulated cannot be empty")
 	}
-
-	var time uint16
-	// processes will be pushed onto this stack as they arrive, and so they will naturally be sorted
-	// from last to first, which is perfect for LCFS
-	processStack := sim.NewStack[*Process](len(*processes))
-	// this is going to be another view into the underlying array, and by slicing it, we are able to
-	// skip iterating over processes that have already arrived before
-	unvisited := *processes
-
-	// if there are processes that have not yet arrived, or ones that are waiting, continue
-	for len(unvisited) != 0 || !processStack.Empty() {
-		for i := range unvisited {
-			if unvisited[i].arriveTime > time {
-				// if a process arrives later than now, we know that all processes that have arrived up to this point have been iterated over
-				// we can remove processes up to this one from our view of the array, as they have already been pushed onto the stack
-				unvisited = unvisited[i:]
-				break
-			}
-			// if a processes has arrived up to now, we push it onto the stack for it to wait for it's turn
-			processStack.Push(&unvisited[i])
-			// if the last process arrives, we need to empty our view
-			if i == len(unvisited)-1 {
-				unvisited = make([]Process, 0)
-			}
-		}
-
-		// if there is a process waiting, we give it execution time
-		if !processStack.Empty() {
-			proc := processStack.Pop()
-			time++
-			proc.executionTimeLeft--
-
-			if proc.executionTimeLeft == 0 {
-				proc.waitTime = time - proc.arriveTime - proc.executionTime
-				continue
-			}
-			// if a process is not done executing we push it back on the stack and check if there are any
-			// newer once since this is preemptive lcfs
-			processStack.Push(proc)
-			continue
-		}
-		// if there are no processes waiting we just wait for them to arrive
-		time++
-	}
-	return processes
-}
-func LCFS(processes *Slice) *Slice {
 	if isSorted := slices.IsSortedFunc([]Process(*processes), func(a, b Process) int {
 		return cmp.Compare(a.arriveTime, b.arriveTime)
 	}); !isSorted {
 		log.Panic("The process scheduling algorithms have to receive a slice of Processes sorted by arriveTime")
 	}
-	if *processes == nil {
-		log.Panic("The process slice to be simulated cannot be nil")
-	}
-	if len(*processes) == 0 {
-		log.Panic("The process slice to be simulated cannot be empty")
+	for _, proc := range *processes {
+		if len(proc.bursts) == 0 {
+			continue
+		}
+		if len(proc.bursts)%2 == 0 || slices.Contains(proc.bursts, 0) {
+			log.Panic("The bursts of a process have to be non zero, and start and end with a cpu burst")
+		}
+		var cpuTime uint64
+		for i := 0; i < len(proc.bursts); i += 2 {
+			cpuTime += uint64(proc.bursts[i])
+		}
+		if cpuTime != uint64(proc.executionTime) {
+			log.Panic("The cpu bursts of a process have to add up to it's execution time")
+		}
 	}
+}
 
-	var time uint16
-	// processes will be pushed onto this stack as they arrive, and so they will naturally be sorted
-	// from last to first, which is perfect for LCFS
-	processStack := sim.NewStack[*Process](len(*processes))
+// run simulates executing the processes on a single cpu that switches between them for free
+func run(processes *Slice, p policy) *Slice {
+	res, _, _ := runUniprocessor(processes, p, Events, switchCosts{})
+	return res
+}
+
+// runUniprocessor simulates executing the processes on a single cpu, every scheduling algorithm shares this loop,
+// and only the policy decides which process gets the cpu, whether it gets preempted, and for how long it can run
+func runUniprocessor(processes *Slice, p policy, engine Engine, costs switchCosts) (*Slice, *CoreSlice, *Timeline) {
+	checkProcesses(processes)
+
+	var time uint32
+	d := newDevice(len(*processes))
+	c := newCPU(0, p, d, costs)
 	// this is going to be another view into the underlying array, and by slicing it, we are able to
 	// skip iterating over processes that have already arrived before
 	unvisited := *processes
 
-	// if there are processes that have not yet arrived, or ones that are waiting, continue
-	for len(unvisited) != 0 || !processStack.Empty() {
+	// if there are processes that have not yet arrived, ones that are waiting, one that is running, or blocked ones, continue
+	for len(unvisited) != 0 || c.busy() || d.busy() {
 		for i := range unvisited {
 			if unvisited[i].arriveTime > time {
 				// if a process arrives later than now, we know that all processes that have arrived up to this point have been iterated over
-				// we can remove processes up to this one from our view of the array, as they have already been pushed onto the stack
+				// we can remove processes up to this one from our view of the array, as they have already been given to the policy
 				unvisited = unvisited[i:]
 				break
 			}
-			// if a processes has arrived up to now, we push it onto the stack for it to wait for it's turn
-			processStack.Push(&unvisited[i])
+			// if a processes has arrived up to now, we give it to the policy for it to wait for it's turn
+			c.add(&unvisited[i], time)
 			// if the last process arrives, we need to empty our view
 			if i == len(unvisited)-1 {
 				unvisited = make([]Process, 0)
 			}
 		}
+		// processes that finished their i/o burst are ready again, and wait behind the ones that just arrived
+		ready, _ := d.unblock()
+		for _, proc := range ready {
+			c.add(proc, time)
+		}
 
-		// if there is a process waiting, we give it execution time
-		if !processStack.Empty() {
-			proc := processStack.Pop()
-			// since this is non-preemptive lcfs we execute the process all at once
-			// we can calculate the wait time right away since the process will be finished
-			proc.waitTime = time - proc.arriveTime
-			for proc.executionTimeLeft > 0 {
-				time++
-				proc.executionTimeLeft--
-			}
-			continue
+		c.schedule()
+		d.schedule()
+		// nothing can change what the cpu and the device are doing until the next event, so we can skip right to it
+		next := min(c.nextEvent(), d.nextEvent())
+		if len(unvisited) != 0 {
+			next = min(next, uint64(unvisited[0].arriveTime-time))
 		}
-		// if there are no processes waiting we just wait for them to arrive
-		time++
+		elapsed := engine.step(time, next)
+		time += elapsed
+		c.execute(time, elapsed)
+		d.execute(time, elapsed)
 	}
-	return processes
+
+	c.stats.utilization = float64(c.stats.busyTime-c.stats.overheadTime) / float64(time)
+	return processes, &CoreSlice{c.stats}, &c.timeline
 }
+
+func PreemptiveLCFS(processes *Slice) *Slice {
+	return run(processes, PreemptiveLCFSPolicy(len(*processes)))
+}
+func LCFS(processes *Slice) *Slice {
+	return run(processes, LCFSPolicy(len(*processes)))
+}
+
+// PreemptiveFCFS re-decides which process gets the cpu after every unit of time, the process that arrived first always wins,
+// and since a newly arrived process can never have arrived before the running one, the results match FCFS
+func PreemptiveFCFS(processes *Slice) *Slice {
+	return run(processes, PreemptiveFCFSPolicy(len(*processes)))
+}
+func FCFS(processes *Slice) *Slice {
+	return run(processes, FCFSPolicy(len(*processes)))
+}
+
 func PreemptiveSJF(processes *Slice) *Slice {
-	if isSorted := slices.IsSortedFunc([]Process(*processes), func(a, b Process) int {
-		return cmp.Compare(a.arriveTime, b.arriveTime)
-	}); !isSorted {
-		log.Panic("The process scheduling algorithms have to receive a slice of Processes sorted by arriveTime")
-	}
-	if *processes == nil {
-		log.Panic("The process slice to be simulated cannot be nil")
-	}
-	if len(*processes) == 0 {
-		log.Panic("The process slice to be simulated cannot be empty")
-	}
+	return run(processes, PreemptiveSJFPolicy(len(*processes)))
+}
+func SJF(processes *Slice) *Slice {
+	return run(processes, SJFPolicy(len(*processes)))
+}
 
-	var time uint16
-	// processes will be pushed onto this heap as they arrive, and they will get sorted by shortest job
-	// I'm using a heap here because the time complexity for heapify is way better than sort
-	// and we only need to know what the shortest job is
-	processHeap := new(Heap)
-	*processHeap = make([]*Process, 0, len(*processes))
-	// this is going to be another view into the underlying array, and by slicing it, we are able to
-	// skip iterating over processes that have already arrived before
-	unvisited := *processes
+// PredictiveSJF returns an Alg that executes the process with the shortest predicted cpu burst until the burst is done,
+// the bursts are predicted by exponential averaging with alpha, starting from initialGuess
+func PredictiveSJF(alpha, initialGuess float64) Alg {
+	return Uniprocessor(PredictiveSJFPolicy(alpha, initialGuess))
+}
 
-	// if there are processes that have not yet arrived, or ones that are waiting, continue
-	for len(unvisited) != 0 || len(*processHeap) != 0 {
-		for i := range unvisited {
-			if unvisited[i].arriveTime > time {
-				// if a process arrives later than now, we know that all processes that have arrived up to this point have been iterated over
-				// we can remove processes up to this one from our view of the array, as they have already been pushed onto the stack
-				unvisited = unvisited[i:]
-				break
-			}
-			// if a processes has arrived up to now, we push it onto the stack for it to wait for it's turn
-			heap.Push(processHeap, &unvisited[i])
-			// if the last process arrives, we need to empty our view
-			if i == len(unvisited)-1 {
-				unvisited = make([]Process, 0)
-			}
-		}
+// PreemptivePredictiveSJF returns an Alg that always executes the process with the shortest predicted time left in it's cpu burst,
+// the bursts are predicted by exponential averaging with alpha, starting from initialGuess
+func PreemptivePredictiveSJF(alpha, initialGuess float64) Alg {
+	return Uniprocessor(PreemptivePredictiveSJFPolicy(alpha, initialGuess))
+}
 
-		// if there is a process waiting, we give it execution time
-		if len(*processHeap) != 0 {
-			proc := heap.Pop(processHeap).(*Process)
-			time++
-			proc.executionTimeLeft--
+// HRRN executes the process with the highest response ratio until it is done
+func HRRN(processes *Slice) *Slice {
+	return run(processes, HRRNPolicy(len(*processes)))
+}
 
-			if proc.executionTimeLeft == 0 {
-				proc.waitTime = time - proc.arriveTime - proc.executionTime
-				continue
-			}
-			// if the process is not done executing we push it back onto the Heap
-			// which also fixes the ordering if it got broken
-			// we will then see if this is still the shortest job
-			heap.Push(processHeap, proc)
-			continue
-		}
-		// if there are no processes waiting we just wait for them to arrive
-		time++
-	}
+// RoundRobin returns an Alg that gives the waiting processes the cpu in turns, for at most quantum units of time each
+func RoundRobin(quantum uint32) Alg {
+	return Uniprocessor(RoundRobinPolicy(quantum))
+}
 
-	return processes
+// PreemptivePriority returns an Alg that always executes the most important waiting process,
+// and takes the cpu away from the running one as soon as a more important one is waiting,
+// a waiting process gains one priority level for every agingRate units of time it waits, 0 disables aging
+func PreemptivePriority(agingRate uint32) Alg {
+	return Uniprocessor(PreemptivePriorityPolicy(agingRate))
 }
-func SJF(processes *Slice) *Slice {
-	if isSorted := slices.IsSortedFunc([]Process(*processes), func(a, b Process) int {
-		return cmp.Compare(a.arriveTime, b.arriveTime)
-	}); !isSorted {
-		log.Panic("The process scheduling algorithms have to receive a slice of Processes sorted by arriveTime")
-	}
-	if *processes == nil {
-		log.Panic("The process slice to be simulated cannot be nil")
-	}
-	if len(*processes) == 0 {
-		log.Panic("The process slice to be simulated cannot be empty")
-	}
 
-	var time uint16
-	// processes will be pushed onto this heap as they arrive, and they will get sorted by shortest job
-	// I'm using a heap here because the time complexity for heapify is way better than sort
-	// and we only need to know what the shortest job is
-	processHeap := new(Heap)
-	*processHeap = make([]*Process, 0, len(*processes))
-	// this is going to be another view into the underlying array, and by slicing it, we are able to
-	// skip iterating over processes that have already arrived before
-	unvisited := *processes
+// Priority returns an Alg that executes the most important waiting process until it is done,
+// a waiting process gains one priority level for every agingRate units of time it waits, 0 disables aging
+func Priority(agingRate uint32) Alg {
+	return Uniprocessor(PriorityPolicy(agingRate))
+}
 
-	// if there are processes that have not yet arrived, or ones that are waiting, continue
-	for len(unvisited) != 0 || len(*processHeap) != 0 {
-		for i := range unvisited {
-			if unvisited[i].arriveTime > time {
-				// if a process arrives later than now, we know that all processes that have arrived up to this point have been iterated over
-				// we can remove processes up to this one from our view of the array, as they have already been pushed onto the stack
-				unvisited = unvisited[i:]
-				break
-			}
-			// if a processes has arrived up to now, we push it onto the stack for it to wait for it's turn
-			heap.Push(processHeap, &unvisited[i])
-			// if the last process arrives, we need to empty our view
-			if i == len(unvisited)-1 {
-				unvisited = make([]Process, 0)
-			}
-		}
+// MLFQ returns an Alg that keeps processes in len(quanta) queues, the first queue is the most important,
+// every process starts in it, and moves one queue down after using up the whole quantum of it's current queue,
+// a process from a more important queue preempts the running one,
+// and every boostInterval units of time all processes are moved back to the first queue, 0 disables the boost
+func MLFQ(quanta []uint32, boostInterval uint32) Alg {
+	return Uniprocessor(MLFQPolicy(quanta, boostInterval))
+}
 
-		// if there is a process waiting, we give it execution time
-		if len(*processHeap) != 0 {
-			proc := heap.Pop(processHeap).(*Process)
-			// we can calculate the wait time right away, since the process will be executed all the way
-			proc.waitTime = time - proc.arriveTime
-			for proc.executionTimeLeft > 0 {
-				time++
-				proc.executionTimeLeft--
-			}
-			continue
-		}
-		// if there are no processes waiting we just wait for them to arrive
-		time++
-	}
-	return processes
+// Lottery returns an Alg that gives the cpu for one quantum to the holder of a randomly drawn ticket,
+// so that on average every process gets a share of the cpu proportional to it's tickets,
+// the tickets are drawn from a generator seeded with seed, so that a simulation can be repeated
+func Lottery(quantum uint32, seed uint64) Alg {
+	return Uniprocessor(LotteryPolicy(quantum, seed))
+}
+
+// Stride returns an Alg that deterministically gives the cpu for one quantum to the process that has used up the least of it's share,
+// every process gets a share of the cpu proportional to it's tickets
+func Stride(quantum uint32) Alg {
+	return Uniprocessor(StridePolicy(quantum))
+}
+
+// CFS returns an Alg modeled after the linux Completely Fair Scheduler, it gives the cpu to the process with the smallest vruntime,
+// for a slice of targetLatency divided between the ready processes according to their weights, but never shorter than minGranularity,
+// a process that becomes ready preempts the running one if it's vruntime is smaller by more than minGranularity
+func CFS(targetLatency, minGranularity uint32) Alg {
+	return Uniprocessor(CFSPolicy(targetLatency, minGranularity))
 }
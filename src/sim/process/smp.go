@@ -0,0 +1,259 @@
+package process
+
+import (
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+)
+
+// Core holds the statistics of a single core from a multi-core simulation
+type Core struct {
+	id       uint32
+	busyTime uint32
+	idleTime uint32
+	// overheadTime is the part of busyTime spent on something other than executing processes, like migrating them
+	overheadTime uint32
+	// utilization is the part of the whole simulation that the core spent executing processes
+	utilization float64
+	finished    uint32
+	// switches is the number of context switches, each of them adds to overheadTime when they are not free
+	switches      uint32
+	migrationsIn  uint32
+	migrationsOut uint32
+}
+
+type CoreSlice []Core
+
+var coreNumFields = reflect.TypeOf(Core{}).NumField()
+
+// Records implements the Recorder interface
+func (s *CoreSlice) Records() (records [][]string) {
+	if s == nil {
+		log.Panic("The slice to get records from cannot be nil")
+	}
+	if len(*s) == 0 {
+		log.Panic("The slice to get records from cannot be empty")
+	}
+
+	records = make([][]string, len(*s)+1)
+	for i := range records {
+		records[i] = make([]string, coreNumFields)
+	}
+
+	for i := range records[0] {
+		records[0][i] = reflect.TypeOf(Core{}).Field(i).Name
+	}
+
+	vals := records[1:]
+	for i, core := range *s {
+		for j := range vals[i] {
+			field := fmt.Sprint(reflect.ValueOf(core).Field(j))
+			vals[i][j] = field
+		}
+	}
+	return records
+}
+
+// Name implements the Namer interface, so that the core statistics can be saved next to the process results
+func (s *CoreSlice) Name() string {
+	return "core"
+}
+
+// Overhead returns the number of context switches of all the cores together,
+// and the time they spent on overhead, like switching and migrating processes
+func (s *CoreSlice) Overhead() (switches uint64, overheadTime uint64) {
+	for _, core := range *s {
+		switches += uint64(core.switches)
+		overheadTime += uint64(core.overheadTime)
+	}
+	return switches, overheadTime
+}
+
+// Balancer is the strategy used to move processes between the run queues of the cores
+type Balancer uint8
+
+const (
+	// NoBalancing leaves every process on the core it was placed on when it arrived
+	NoBalancing Balancer = iota
+	// PushMigration periodically moves processes from the core with the most of them to the one with the least,
+	// until their loads differ by at most one
+	PushMigration
+	// PullMigration makes a core that ran out of processes take one from the core with the most waiting
+	PullMigration
+	// WorkStealing makes a core that ran out of processes pick other cores in random order,
+	// and take half of the waiting processes from the first one that has some to spare
+	WorkStealing
+)
+
+var balancerNames = [...]string{"none", "push", "pull", "steal"}
+
+func (b Balancer) String() string {
+	if int(b) >= len(balancerNames) {
+		return fmt.Sprint("Balancer(", uint8(b), ")")
+	}
+	return balancerNames[b]
+}
+
+// ParseBalancer returns the Balancer with the given name, as returned by String
+func ParseBalancer(name string) (b Balancer, ok bool) {
+	i := slices.Index(balancerNames[:], name)
+	if i == -1 {
+		return NoBalancing, false
+	}
+	return Balancer(i), true
+}
+
+// SimSMP runs a simulation of the given processes on numCores cores, every core using it's own instance of a policy,
+// it is done once for each of the policies, balanceInterval is only used by PushMigration,
+// migrationCost is the time a core spends on a process that was moved to it, before it can start executing it,
+// and switchCost and addressSpaceCost are the costs of a context switch, the second one only paid between address spaces
+func SimSMP(processes *Slice, engine Engine, numCores uint32, balancer Balancer, balanceInterval, migrationCost uint32,
+	switchCost, addressSpaceCost uint32, policies ...Policy) (res []*Slice, cores []*CoreSlice, timelines []*Timeline) {
+	if numCores == 0 {
+		log.Panic("The number of cores to simulate cannot be zero")
+	}
+	if balancer == PushMigration && balanceInterval == 0 {
+		log.Panic("Push migration needs a balance interval greater than zero")
+	}
+	res = make([]*Slice, len(policies))
+	cores = make([]*CoreSlice, len(policies))
+	timelines = make([]*Timeline, len(policies))
+
+	for i, newPolicy := range policies {
+		res[i], cores[i], timelines[i] = runSMP(processes.Copy(), engine, numCores, newPolicy, balancer, balanceInterval, migrationCost,
+			switchCosts{switchCost, addressSpaceCost})
+	}
+	return res, cores, timelines
+}
+
+// runSMP simulates executing the processes on numCores cpus that share the same clock, but have separate run queues
+func runSMP(processes *Slice, engine Engine, numCores uint32, newPolicy Policy, balancer Balancer, balanceInterval, migrationCost uint32,
+	costs switchCosts) (*Slice, *CoreSlice, *Timeline) {
+	checkProcesses(processes)
+
+	pendingOverhead := make(map[*Process]uint32)
+	// all the cores share a single i/o device
+	d := newDevice(len(*processes))
+	cpus := make([]*cpu, numCores)
+	for i := range cpus {
+		cpus[i] = newCPU(uint32(i), newPolicy(len(*processes)), d, costs)
+		cpus[i].pendingOverhead = pendingOverhead
+	}
+	// the victims for work stealing are picked randomly, but with a fixed seed, so that a simulation can be repeated
+	rng := rand.New(rand.NewPCG(uint64(numCores), uint64(len(*processes))))
+	var time uint32
+	migrate := func(from, to *cpu) {
+		proc := from.take(time)
+		pendingOverhead[proc] += migrationCost
+		to.add(proc, time)
+		from.stats.migrationsOut++
+		to.stats.migrationsIn++
+	}
+	// a core can only give away the processes it has waiting, and it cannot be left without anything to do
+	spare := func(c *cpu) int {
+		return min(c.policy.len(), c.load()-1)
+	}
+	canGive := func(c *cpu) bool {
+		return spare(c) > 0
+	}
+
+	// new processes are placed on the cores in turns without looking at their load, so that the balancer has something to do
+	var nextCore int
+	// this is going to be another view into the underlying array, and by slicing it, we are able to
+	// skip iterating over processes that have already arrived before
+	unvisited := *processes
+
+	// if there are processes that have not yet arrived, any core has something to do, or there are blocked ones, continue
+	for len(unvisited) != 0 || slices.ContainsFunc(cpus, (*cpu).busy) || d.busy() {
+		for i := range unvisited {
+			if unvisited[i].arriveTime > time {
+				unvisited = unvisited[i:]
+				break
+			}
+			cpus[nextCore].add(&unvisited[i], time)
+			nextCore = (nextCore + 1) % len(cpus)
+			if i == len(unvisited)-1 {
+				unvisited = make([]Process, 0)
+			}
+		}
+		// processes that finished their i/o burst go back to the core they blocked on,
+		// so that only the balancer moves processes between cores, and they pay for it
+		ready, readyCores := d.unblock()
+		for i, proc := range ready {
+			cpus[readyCores[i]].add(proc, time)
+		}
+
+		switch balancer {
+		case PushMigration:
+			if time%balanceInterval != 0 {
+				break
+			}
+			for {
+				busiest := slices.MaxFunc(cpus, func(a, b *cpu) int { return a.load() - b.load() })
+				idlest := slices.MinFunc(cpus, func(a, b *cpu) int { return a.load() - b.load() })
+				if busiest.load()-idlest.load() <= 1 || !canGive(busiest) {
+					break
+				}
+				migrate(busiest, idlest)
+			}
+		case PullMigration:
+			for _, c := range cpus {
+				if c.busy() {
+					continue
+				}
+				// a core that was just given a process has it waiting, but cannot spare it, so it is not the busiest one
+				busiest := slices.MaxFunc(cpus, func(a, b *cpu) int { return spare(a) - spare(b) })
+				if canGive(busiest) {
+					migrate(busiest, c)
+				}
+			}
+		case WorkStealing:
+			for _, c := range cpus {
+				// the victims are only drawn when there is something to steal, so that the draws do not depend on the engine
+				if c.busy() || !slices.ContainsFunc(cpus, canGive) {
+					continue
+				}
+				for _, i := range rng.Perm(len(cpus)) {
+					if victim := cpus[i]; victim != c && canGive(victim) {
+						for range (victim.policy.len() + 1) / 2 {
+							migrate(victim, c)
+						}
+						break
+					}
+				}
+			}
+		}
+
+		for _, c := range cpus {
+			c.schedule()
+		}
+		d.schedule()
+		next := d.nextEvent()
+		for _, c := range cpus {
+			next = min(next, c.nextEvent())
+		}
+		if len(unvisited) != 0 {
+			next = min(next, uint64(unvisited[0].arriveTime-time))
+		}
+		if balancer == PushMigration {
+			next = min(next, uint64(balanceInterval-time%balanceInterval))
+		}
+		elapsed := engine.step(time, next)
+		time += elapsed
+		for _, c := range cpus {
+			c.execute(time, elapsed)
+		}
+		d.execute(time, elapsed)
+	}
+
+	cores := make(CoreSlice, len(cpus))
+	var timeline Timeline
+	for i, c := range cpus {
+		cores[i] = c.stats
+		cores[i].utilization = float64(c.stats.busyTime-c.stats.overheadTime) / float64(time)
+		timeline = append(timeline, c.timeline...)
+	}
+	return processes, &cores, &timeline
+}
@@ -0,0 +1,123 @@
+package process
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"slices"
+)
+
+// Summary holds the aggregate statistics of a single algorithm run, so that the algorithms can be compared without a notebook
+type Summary struct {
+	alg string
+	// the wait and turnaround percentiles use the nearest rank method
+	meanWait         float64
+	medianWait       uint32
+	p95Wait          uint32
+	p99Wait          uint32
+	maxWait          uint32
+	meanTurnaround   float64
+	medianTurnaround uint32
+	p95Turnaround    uint32
+	p99Turnaround    uint32
+	maxTurnaround    uint32
+	// makespan is the time at which the last process finished, and throughput is the number of processes finished per unit of time
+	makespan   uint32
+	throughput float64
+	// utilization is the part of the makespan all the cpus together spent executing processes, and idleTime is the sum of their idle time
+	utilization float64
+	idleTime    uint64
+	// fairness is Jain's fairness index of the cpu shares of the processes, 1 when they are all the same, and 1/n at worst
+	fairness float64
+}
+
+// Summarize computes the Summary of the results of a run of alg, on the cpus described by cores
+func Summarize(alg string, processes *Slice, cores *CoreSlice) (res Summary) {
+	if processes == nil || len(*processes) == 0 {
+		log.Panic("The process slice to summarize cannot be nil or empty")
+	}
+	if cores == nil || len(*cores) == 0 {
+		log.Panic("The core slice to summarize cannot be nil or empty")
+	}
+
+	res.alg = alg
+	n := len(*processes)
+	waits := make([]uint32, 0, n)
+	turnarounds := make([]uint32, 0, n)
+	var sumShare, sumShareSquared float64
+	for _, proc := range *processes {
+		waits = append(waits, proc.waitTime)
+		turnarounds = append(turnarounds, proc.turnaroundTime)
+		res.makespan = max(res.makespan, proc.completionTime)
+		sumShare += proc.cpuShare
+		sumShareSquared += proc.cpuShare * proc.cpuShare
+	}
+	res.meanWait, res.medianWait, res.p95Wait, res.p99Wait, res.maxWait = distribution(waits)
+	res.meanTurnaround, res.medianTurnaround, res.p95Turnaround, res.p99Turnaround, res.maxTurnaround = distribution(turnarounds)
+	res.fairness = sumShare * sumShare / (float64(n) * sumShareSquared)
+
+	var executing uint64
+	for _, core := range *cores {
+		executing += uint64(core.busyTime - core.overheadTime)
+		res.idleTime += uint64(core.idleTime)
+	}
+	if res.makespan != 0 {
+		res.throughput = float64(n) / float64(res.makespan)
+		res.utilization = float64(executing) / (float64(len(*cores)) * float64(res.makespan))
+	}
+	return res
+}
+
+// distribution returns the mean, median, 95th and 99th percentile and maximum of vals, it sorts vals in place
+func distribution(vals []uint32) (mean float64, median, p95, p99, maximum uint32) {
+	slices.Sort(vals)
+	var sum float64
+	for _, val := range vals {
+		sum += float64(val)
+	}
+	return sum / float64(len(vals)), percentile(vals, 50), percentile(vals, 95), percentile(vals, 99), vals[len(vals)-1]
+}
+
+// percentile returns the smallest of the sorted vals that is not smaller than p percent of them
+func percentile(sorted []uint32, p float64) uint32 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+type SummarySlice []Summary
+
+var summaryNumFields = reflect.TypeOf(Summary{}).NumField()
+
+// Records implements the Recorder interface
+func (s *SummarySlice) Records() (records [][]string) {
+	if s == nil {
+		log.Panic("The slice to get records from cannot be nil")
+	}
+	if len(*s) == 0 {
+		log.Panic("The slice to get records from cannot be empty")
+	}
+
+	records = make([][]string, len(*s)+1)
+	for i := range records {
+		records[i] = make([]string, summaryNumFields)
+	}
+
+	for i := range records[0] {
+		records[0][i] = reflect.TypeOf(Summary{}).Field(i).Name
+	}
+
+	vals := records[1:]
+	for i, summary := range *s {
+		for j := range vals[i] {
+			field := fmt.Sprint(reflect.ValueOf(summary).Field(j))
+			vals[i][j] = field
+		}
+	}
+	return records
+}
+
+// Name implements the Namer interface, so that the summary is not mistaken for the process results
+func (s *SummarySlice) Name() string {
+	return "summary"
+}
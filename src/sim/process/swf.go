@@ -0,0 +1,117 @@
+package process
+
+import (
+	"bufio"
+	"cmp"
+	"log"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// the fields of a job in the Standard Workload Format of the Parallel Workloads Archive that we use, counted from 0,
+// every line of a trace is a job, and fields that are not known are -1
+const (
+	SWF_SUBMIT_TIME          = 1
+	SWF_RUN_TIME             = 3
+	SWF_ALLOCATED_PROCESSORS = 4
+	SWF_REQUESTED_PROCESSORS = 7
+	SWF_NUM_FIELDS           = 18
+)
+
+// LoadSWF reads the jobs of a trace in the Standard Workload Format (https://www.cs.huji.ac.il/labs/parallel/workload/swf.html)
+// from the file at path, and turns them into processes, the submit time of a job becomes the arrive time, and it's run time
+// the execution time, both of them in seconds multiplied by timeScale, so that for example 1/60 makes a unit of time a minute,
+// a job gets a process for every processor it requested, up to maxThreads, all of them in the job's own address space,
+// like threads of the same program, only the first maxJobs jobs are read, 0 reads all of them,
+// jobs that were cancelled before they ran, or are missing their submit or run time, are left out
+func LoadSWF(path string, timeScale float64, maxJobs uint32, maxThreads uint32) *Slice {
+	if timeScale <= 0 {
+		log.Panic("The SWF time scale has to be greater than zero")
+	}
+	if maxThreads == 0 {
+		log.Panic("A job has to get at least one process")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		log.Panic(err)
+	}
+	defer file.Close()
+
+	var processes Slice
+	var jobs uint32
+	// the traces do not always start at 0, so the arrive times start from the first submit time
+	firstSubmit := int64(math.MaxInt64)
+	submitTimes := make([]int64, 0)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan() && (maxJobs == 0 || jobs < maxJobs); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		// the header of the trace is made of comments
+		if text == "" || text[0] == ';' {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != SWF_NUM_FIELDS {
+			log.Panicf("%s:%d: a SWF job has to have %d fields, got %d", path, line, SWF_NUM_FIELDS, len(fields))
+		}
+		vals := make([]int64, len(fields))
+		for i, field := range fields {
+			// a few traces have fractions in some of the fields, so we read them as floats and round them
+			val, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				log.Panicf("%s:%d: field %d of a SWF job has to be a number, got: %q", path, line, i+1, field)
+			}
+			vals[i] = int64(math.Round(val))
+		}
+
+		submit, run := vals[SWF_SUBMIT_TIME], vals[SWF_RUN_TIME]
+		if submit < 0 || run <= 0 {
+			continue
+		}
+		threads := vals[SWF_REQUESTED_PROCESSORS]
+		if threads <= 0 {
+			threads = vals[SWF_ALLOCATED_PROCESSORS]
+		}
+		threads = min(max(threads, 1), int64(maxThreads))
+
+		// a job that is shorter than a unit of time after scaling still has to execute for one
+		executionTime := max(1, math.Round(float64(run)*timeScale))
+		if executionTime > math.MaxUint32 {
+			log.Panicf("%s:%d: the run time of the job does not fit in 32 bits after scaling it by %g", path, line, timeScale)
+		}
+		for range threads {
+			processes = append(processes, Process{id: uint32(len(processes)),
+				executionTime:     uint32(executionTime),
+				executionTimeLeft: uint32(executionTime),
+				tickets:           1,
+				addressSpace:      jobs})
+			submitTimes = append(submitTimes, submit)
+		}
+		firstSubmit = min(firstSubmit, submit)
+		jobs++
+		if len(processes) > math.MaxUint32 {
+			log.Panicf("%s:%d: the trace has more processes than fit in 32 bits", path, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Panic(err)
+	}
+	if len(processes) == 0 {
+		log.Panicf("%s: the trace has no jobs that ran in it", path)
+	}
+
+	for i, submit := range submitTimes {
+		arriveTime := math.Round(float64(submit-firstSubmit) * timeScale)
+		if arriveTime > math.MaxUint32 {
+			log.Panicf("%s: the submit time %d does not fit in 32 bits after scaling it by %g", path, submit, timeScale)
+		}
+		processes[i].arriveTime = uint32(arriveTime)
+	}
+	// the jobs in a trace are sorted by their submit times, but we do not rely on it
+	slices.SortStableFunc(processes, func(a, b Process) int {
+		return cmp.Compare(a.arriveTime, b.arriveTime)
+	})
+	return &processes
+}
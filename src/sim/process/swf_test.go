@@ -0,0 +1,59 @@
+package process
+
+import "testing"
+
+// the fields of a job are: job number, submit time, wait time, run time, allocated processors, average cpu time used,
+// used memory, requested processors, requested time, requested memory, status, user, group, executable, queue, partition,
+// preceding job and think time
+const testTrace = `; Version: 2.2
+; Computer: a test machine
+; MaxProcs: 8
+
+1   100  5  30    2 -1 -1  2 60 -1 1 1 1 1 1 1 -1 -1
+; a job that was cancelled before it ran has no run time
+2   110  0  -1   -1 -1 -1  1 60 -1 5 1 1 1 1 1 -1 -1
+3   130  2   0    4 -1 -1  4 60 -1 5 1 1 1 1 1 -1 -1
+; no processor count is known, so the job gets a single process
+4   160  1  45   -1 -1 -1 -1 60 -1 1 2 1 1 1 1 -1 -1
+; a fraction is rounded to 15 seconds, and the job is cut down to maxThreads processes
+5   175  0  14.6  8 -1 -1  8 60 -1 1 2 1 1 1 1 -1 -1
+6    -1  0  10    1 -1 -1  1 60 -1 1 2 1 1 1 1 -1 -1
+; a job shorter than a unit of time after scaling still executes for one
+7   176  0   2   -1 -1 -1  1 60 -1 1 3 1 1 1 1 -1 -1
+`
+
+func TestLoadSWF(t *testing.T) {
+	path := writeFile(t, "trace.swf", testTrace)
+	want := []struct{ id, arriveTime, executionTime, addressSpace uint32 }{
+		// job 1 arrives first, at 0, and runs for 30 seconds, or 3 units of time, on it's 2 processors
+		{0, 0, 3, 0}, {1, 0, 3, 0},
+		// job 4 is submitted 60 seconds after it, and runs for 4.5 units of time, both rounded to the nearest unit
+		{2, 6, 5, 1},
+		// 7.5 and 1.5 units of time round up
+		{3, 8, 2, 2}, {4, 8, 2, 2}, {5, 8, 2, 2},
+		{6, 8, 1, 3},
+	}
+
+	processes := *LoadSWF(path, 0.1, 0, 3)
+	if len(processes) != len(want) {
+		t.Fatalf("loaded %d processes, want %d", len(processes), len(want))
+	}
+	for i, proc := range processes {
+		if proc.id != want[i].id || proc.arriveTime != want[i].arriveTime || proc.executionTime != want[i].executionTime ||
+			proc.addressSpace != want[i].addressSpace {
+			t.Errorf("process %d is %d,%d,%d in address space %d, want %v", i, proc.id, proc.arriveTime, proc.executionTime, proc.addressSpace, want[i])
+		}
+		if proc.executionTimeLeft != proc.executionTime || proc.tickets != 1 {
+			t.Errorf("process %d is not ready to be simulated", i)
+		}
+	}
+
+	// only the jobs that ran count towards maxJobs
+	if processes := *LoadSWF(path, 0.1, 2, 3); len(processes) != 3 {
+		t.Errorf("loaded %d processes from the first 2 jobs, want 3", len(processes))
+	}
+	// without scaling, a unit of time is a second
+	if processes := *LoadSWF(path, 1, 1, 1); len(processes) != 1 || processes[0].executionTime != 30 {
+		t.Errorf("loaded %v from the first job with one thread, want a process that executes for 30", processes)
+	}
+}
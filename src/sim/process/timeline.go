@@ -0,0 +1,211 @@
+package process
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"slices"
+	"src/sim/trace"
+	"strings"
+)
+
+// the states a cpu can be in during a Segment
+const (
+	RUNNING  = "running"
+	OVERHEAD = "overhead"
+	IDLE     = "idle"
+)
+
+// Segment is an interval of time during which a cpu kept doing the same thing
+type Segment struct {
+	core  uint32
+	start uint32
+	end   uint32
+	state string
+	// process is the id of the process that was executing, or that the overhead was spent on, -1 when the cpu was idle
+	process int64
+}
+
+// Timeline is the Gantt chart of a simulation, the segments of every core are sorted by their start
+type Timeline []Segment
+
+var segmentNumFields = reflect.TypeOf(Segment{}).NumField()
+
+// Records implements the Recorder interface
+func (t *Timeline) Records() (records [][]string) {
+	if t == nil {
+		log.Panic("The timeline to get records from cannot be nil")
+	}
+	if len(*t) == 0 {
+		log.Panic("The timeline to get records from cannot be empty")
+	}
+
+	records = make([][]string, len(*t)+1)
+	for i := range records {
+		records[i] = make([]string, segmentNumFields)
+	}
+
+	for i := range records[0] {
+		records[0][i] = reflect.TypeOf(Segment{}).Field(i).Name
+	}
+
+	vals := records[1:]
+	for i, segment := range *t {
+		for j := range vals[i] {
+			field := fmt.Sprint(reflect.ValueOf(segment).Field(j))
+			vals[i][j] = field
+		}
+	}
+	return records
+}
+
+// Name implements the Namer interface, so that the timeline is saved next to the process results
+func (t *Timeline) Name() string {
+	return "timeline"
+}
+
+// GANTT_WIDTH is the number of characters in a single row of the rendered Gantt chart
+const GANTT_WIDTH = 100
+
+// GANTT_ROWS is the most rows the rendered Gantt chart can have, longer simulations are scaled down to fit in them
+const GANTT_ROWS = 10
+
+// Render implements the Renderer interface, it draws the timeline as a text Gantt chart with one character for every unit of time,
+// a process is drawn as a bar starting with a '|' followed by it's id, overhead as '#' and idle time as '.',
+// the chart is split into rows of GANTT_WIDTH characters, so that it can be read without scrolling sideways,
+// when it would take more than GANTT_ROWS of them, every character stands for as many units of time as it takes to fit,
+// and shows the segment that took up the most of them, so that the file does not grow with the length of the simulation
+func (t *Timeline) Render() string {
+	if t == nil || len(*t) == 0 {
+		log.Panic("The timeline to render cannot be nil or empty")
+	}
+
+	var numCores uint32
+	var end uint64
+	for _, segment := range *t {
+		numCores = max(numCores, segment.core+1)
+		end = max(end, uint64(segment.end))
+	}
+	scale := max(1, (end+GANTT_WIDTH*GANTT_ROWS-1)/(GANTT_WIDTH*GANTT_ROWS))
+	columns := int((end + scale - 1) / scale)
+
+	// the segment that covers the most of every character of every core, and for how long, -1 where there is none
+	shown := make([][]int, numCores)
+	covered := make([][]uint64, numCores)
+	for core := range shown {
+		shown[core] = slices.Repeat([]int{-1}, columns)
+		covered[core] = make([]uint64, columns)
+	}
+	for i, segment := range *t {
+		start, end := uint64(segment.start), uint64(segment.end)
+		for column := start / scale; column*scale < end; column++ {
+			if overlap := min(end, (column+1)*scale) - max(start, column*scale); overlap > covered[segment.core][column] {
+				shown[segment.core][column], covered[segment.core][column] = i, overlap
+			}
+		}
+	}
+
+	// every core gets a line of the whole chart, and then they are cut into rows
+	lines := make([][]byte, numCores)
+	for core := range lines {
+		lines[core] = []byte(strings.Repeat(" ", columns))
+		for column := 0; column < columns; {
+			next := column + 1
+			for next < columns && shown[core][next] == shown[core][column] {
+				next++
+			}
+			if shown[core][column] != -1 {
+				segment := (*t)[shown[core][column]]
+				bar := lines[core][column:next]
+				switch segment.state {
+				case RUNNING:
+					label := fmt.Sprint("|", segment.process)
+					for i := range bar {
+						bar[i] = '='
+					}
+					copy(bar, label)
+				case OVERHEAD:
+					for i := range bar {
+						bar[i] = '#'
+					}
+				case IDLE:
+					for i := range bar {
+						bar[i] = '.'
+					}
+				}
+			}
+			column = next
+		}
+	}
+
+	label := fmt.Sprint("cpu ", numCores-1, " ")
+	var b strings.Builder
+	b.WriteString("|id= process executing, # overhead, . idle")
+	if scale > 1 {
+		b.WriteString(fmt.Sprint(", every character is ", scale, " units of time"))
+	}
+	b.WriteString("\n")
+	for start := 0; start < columns; start += GANTT_WIDTH {
+		stop := min(start+GANTT_WIDTH, columns)
+		b.WriteString("\n")
+		b.WriteString(strings.Repeat(" ", len(label)))
+		for column := start; column < stop; column += 10 {
+			b.WriteString(fmt.Sprintf("%-10d", uint64(column)*scale))
+		}
+		b.WriteString("\n")
+		for core, line := range lines {
+			b.WriteString(fmt.Sprintf("%-*s", len(label), fmt.Sprint("cpu ", core, " ")))
+			b.Write(line[start:stop])
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// Trace converts the timeline of the simulation of processes into a Chrome trace named name, with a track for every cpu,
+// the arrivals of the processes are instant events across all the tracks, and their completions on the track of the cpu that finished them,
+// idle time is left out, it is just the gaps between the other events
+func (t *Timeline) Trace(processes *Slice, name string) *trace.Trace {
+	if t == nil || len(*t) == 0 {
+		log.Panic("The timeline to trace cannot be nil or empty")
+	}
+	if processes == nil || len(*processes) == 0 {
+		log.Panic("The processes to trace cannot be nil or empty")
+	}
+
+	res := trace.New()
+	res.NameProcess(0, name)
+	named := make(map[uint32]bool)
+	// the core that finished every process, which is the one that executed the last unit of time before it completed
+	finishedOn := make(map[int64]uint32)
+	completions := make(map[int64]uint32, len(*processes))
+	for _, proc := range *processes {
+		completions[int64(proc.id)] = proc.completionTime
+	}
+	for _, segment := range *t {
+		if !named[segment.core] {
+			named[segment.core] = true
+			res.NameTrack(0, segment.core, fmt.Sprint("cpu ", segment.core))
+		}
+		switch segment.state {
+		case RUNNING:
+			res.Span(fmt.Sprint("process ", segment.process), RUNNING, 0, segment.core, uint64(segment.start), uint64(segment.end),
+				map[string]any{"process": segment.process})
+			if completions[segment.process] == segment.end {
+				finishedOn[segment.process] = segment.core
+			}
+		case OVERHEAD:
+			res.Span(OVERHEAD, OVERHEAD, 0, segment.core, uint64(segment.start), uint64(segment.end),
+				map[string]any{"process": segment.process})
+		}
+	}
+
+	for _, proc := range *processes {
+		args := map[string]any{"process": proc.id}
+		res.Instant(fmt.Sprint("process ", proc.id, " arrived"), "arrival", 0, 0, uint64(proc.arriveTime), trace.PROCESS_SCOPE, args)
+		if core, ok := finishedOn[int64(proc.id)]; ok {
+			res.Instant(fmt.Sprint("process ", proc.id, " completed"), "completion", 0, core, uint64(proc.completionTime), trace.THREAD_SCOPE, args)
+		}
+	}
+	return res
+}
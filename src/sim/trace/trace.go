@@ -0,0 +1,77 @@
+package trace
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+)
+
+// the event phases of the Chrome Trace Event format that we use
+const (
+	COMPLETE = "X"
+	INSTANT  = "i"
+	METADATA = "M"
+)
+
+// the scopes of instant events, they decide if the event is drawn on a single track, or across all tracks of a process
+const (
+	THREAD_SCOPE  = "t"
+	PROCESS_SCOPE = "p"
+)
+
+// Event is a single event of the Chrome Trace Event format, the fields have to be exported to be encoded as json,
+// one unit of simulation time is stored as a microsecond, because that is the unit of ts and dur
+type Event struct {
+	Name  string         `json:"name"`
+	Cat   string         `json:"cat,omitempty"`
+	Ph    string         `json:"ph"`
+	Ts    uint64         `json:"ts"`
+	Dur   uint64         `json:"dur,omitempty"`
+	Pid   uint32         `json:"pid"`
+	Tid   uint32         `json:"tid"`
+	Scope string         `json:"s,omitempty"`
+	Args  map[string]any `json:"args,omitempty"`
+}
+
+// Trace is a json trace that can be opened in Perfetto or chrome://tracing, every simulation is shown as a trace process,
+// and every cpu, or anything else that happens over time, as a track (a thread) in it
+type Trace struct {
+	TraceEvents []Event `json:"traceEvents"`
+}
+
+func New() *Trace {
+	return &Trace{TraceEvents: make([]Event, 0)}
+}
+
+// NameProcess sets the name under which the trace process pid is shown
+func (t *Trace) NameProcess(pid uint32, name string) {
+	t.TraceEvents = append(t.TraceEvents, Event{Name: "process_name", Ph: METADATA, Pid: pid, Args: map[string]any{"name": name}})
+}
+
+// NameTrack sets the name under which the track tid of the trace process pid is shown
+func (t *Trace) NameTrack(pid, tid uint32, name string) {
+	t.TraceEvents = append(t.TraceEvents, Event{Name: "thread_name", Ph: METADATA, Pid: pid, Tid: tid, Args: map[string]any{"name": name}})
+}
+
+// Span adds an event that lasts from start to end on the track tid
+func (t *Trace) Span(name, cat string, pid, tid uint32, start, end uint64, args map[string]any) {
+	if end < start {
+		log.Panic("A trace span cannot end before it starts")
+	}
+	t.TraceEvents = append(t.TraceEvents, Event{Name: name, Cat: cat, Ph: COMPLETE, Ts: start, Dur: end - start, Pid: pid, Tid: tid, Args: args})
+}
+
+// Instant adds an event that happens at ts, on the track tid, or on all the tracks of pid with PROCESS_SCOPE
+func (t *Trace) Instant(name, cat string, pid, tid uint32, ts uint64, scope string, args map[string]any) {
+	t.TraceEvents = append(t.TraceEvents, Event{Name: name, Cat: cat, Ph: INSTANT, Ts: ts, Pid: pid, Tid: tid, Scope: scope, Args: args})
+}
+
+// Write encodes the trace as json into w
+func (t *Trace) Write(w io.Writer) {
+	if t == nil {
+		log.Panic("The trace to write cannot be nil")
+	}
+	if err := json.NewEncoder(w).Encode(t); err != nil {
+		log.Panic(err)
+	}
+}